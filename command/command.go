@@ -22,6 +22,7 @@ import (
 	"github.com/squareup/pranadb/errors"
 	"github.com/squareup/pranadb/execctx"
 	"github.com/squareup/pranadb/meta"
+	"github.com/squareup/pranadb/parplan"
 	"github.com/squareup/pranadb/protolib"
 	"github.com/squareup/pranadb/pull"
 	"github.com/squareup/pranadb/pull/exec"
@@ -90,11 +91,18 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 		}
 		return nil, errors.WithStack(err)
 	}
+	if ast.Select == "" && ast.Explain == nil && len(ast.Parameters) > 0 {
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Bind parameters are only supported in SELECT statements")
+	}
 
 	switch {
+	case ast.Empty:
+		return exec.Empty, nil
 	case ast.Select != "":
 		dag, err := e.pullEngine.BuildPullQuery(execCtx, sql, argTypes, args)
 		return dag, errors.WithStack(err)
+	case ast.Explain != nil:
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "EXPLAIN is not supported")
 	case ast.Create != nil && ast.Create.Source != nil:
 		// We need two sequence numbers if the source has a retention period as we create an index for that
 		sequences, err := e.generateTableIDSequences(2)
@@ -112,6 +120,15 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 		}
 		return exec.Empty, nil
 	case ast.Create != nil && ast.Create.MaterializedView != nil:
+		if err := ast.Create.MaterializedView.Validate(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if ast.Create.MaterializedView.Window != nil {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "WINDOW is not supported")
+		}
+		if strings.EqualFold(ast.Create.MaterializedView.Emit, parser.EmitFinal) {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "EMIT FINAL is not supported")
+		}
 		if err := e.executeCommandWithRetry(execCtx.Ctx, func() (DDLCommand, error) {
 			sequences, err := e.generateTableIDSequences(3)
 			if err != nil {
@@ -142,12 +159,20 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 		}
 		return exec.Empty, nil
 	case ast.Create != nil && ast.Create.Index != nil:
+		schema, err := e.schemaForRef(execCtx, ast.Create.Index.TableName)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		pl := execCtx.Planner()
+		if schema != execCtx.Schema {
+			pl = parplan.NewPlanner(schema)
+		}
 		if err := e.executeCommandWithRetry(execCtx.Ctx, func() (DDLCommand, error) {
 			sequences, err := e.generateTableIDSequences(1)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
-			command, err := NewOriginatingCreateIndexCommand(e, execCtx.Planner(), execCtx.Schema, sql, sequences, ast.Create.Index)
+			command, err := NewOriginatingCreateIndexCommand(e, pl, schema, sql, sequences, ast.Create.Index)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
@@ -178,7 +203,8 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 		}
 		return exec.Empty, nil
 	case ast.Drop != nil && ast.Drop.Index:
-		command := NewOriginatingDropIndexCommand(e, execCtx.Schema.Name, sql, ast.Drop.TableName, ast.Drop.Name)
+		schemaName := strings.ToLower(ast.Drop.TableName.Schema(execCtx.Schema.Name))
+		command := NewOriginatingDropIndexCommand(e, schemaName, sql, ast.Drop.TableName.Name(), ast.Drop.Name)
 		err = e.ddlRunner.RunCommand(execCtx.Ctx, command)
 		if err != nil {
 			return nil, errors.WithStack(err)
@@ -196,28 +222,48 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 			return nil, errors.WithStack(err)
 		}
 		return rows, nil
+	case ast.Show != nil && ast.Show.Full:
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "SHOW FULL INDEXES is not supported")
 	case ast.Show != nil && ast.Show.Indexes:
-		rows, err := e.execShowIndexes(execCtx.Schema.Name, ast.Show.TableName)
+		schemaName := strings.ToLower(ast.Show.TableName.Schema(execCtx.Schema.Name))
+		rows, err := e.execShowIndexes(schemaName, ast.Show.TableName.Name())
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 		return rows, nil
+	case ast.Show != nil && ast.Show.Stats:
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "SHOW STATS is not supported")
 	case ast.Describe != "":
 		rows, err := e.execDescribe(execCtx, strings.ToLower(ast.Describe))
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 		return rows, nil
-	case ast.ResetDdl != "":
-		if err := e.DDlCommandRunner().Cancel(ast.ResetDdl); err != nil {
+	case ast.ResetDdl != nil:
+		schemaName := strings.ToLower(ast.ResetDdl.Schema)
+		// IF EXISTS only suppresses the error for an unknown schema, it still cancels: a schema isn't created until its
+		// first DDL completes, and that DDL may be the one holding the lock.
+		if _, ok := e.metaController.GetSchema(schemaName); !ok && !ast.ResetDdl.IfExists && schemaName != execCtx.Schema.Name {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Unknown schema %s", schemaName)
+		}
+		if err := e.DDlCommandRunner().Cancel(schemaName); err != nil {
 			return nil, errors.WithStack(err)
 		}
 		return exec.Empty, nil
+	case ast.Grant != nil:
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "GRANT is not supported")
+	case ast.Revoke != nil:
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "REVOKE is not supported")
 	case ast.SourceSetMaxRate != nil:
+		if *ast.SourceSetMaxRate.Burst != ast.SourceSetMaxRate.Rate {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "BURST is not supported")
+		}
 		if err := e.execSetMaxSourceIngestRate(execCtx, ast.SourceSetMaxRate.SourceName, ast.SourceSetMaxRate.Rate); err != nil {
 			return nil, errors.WithStack(err)
 		}
 		return exec.Empty, nil
+	case ast.RebuildIndex != nil:
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "REBUILD INDEX is not supported")
 	}
 	return nil, errors.Errorf("invalid statement %s", sql)
 }
@@ -266,6 +312,20 @@ func (e *Executor) executeCommandWithRetry(ctx context.Context, commandFactory f
 	}
 }
 
+// schemaForRef returns the schema a table reference belongs to, which is the schema of the execution context unless
+// the reference is schema qualified.
+func (e *Executor) schemaForRef(execCtx *execctx.ExecutionContext, ref *parser.Ref) (*common.Schema, error) {
+	schemaName := strings.ToLower(ref.Schema(execCtx.Schema.Name))
+	if schemaName == execCtx.Schema.Name {
+		return execCtx.Schema, nil
+	}
+	schema, ok := e.metaController.GetSchema(schemaName)
+	if !ok {
+		return nil, errors.NewUnknownTableError(schemaName, ref.Name())
+	}
+	return schema, nil
+}
+
 func (e *Executor) generateTableIDSequences(numValues int) ([]uint64, error) {
 	tableIDSequences := make([]uint64, numValues)
 	for i := 0; i < numValues; i++ {
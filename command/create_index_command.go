@@ -185,12 +185,15 @@ func (c *CreateIndexCommand) Cleanup() {
 
 func (c *CreateIndexCommand) getIndexInfo(ast *parser.CreateIndex) (*common.IndexInfo, error) {
 	ast.Name = strings.ToLower(ast.Name)
+	if ast.Filter != nil {
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Partial indexes are not supported")
+	}
 	var tab common.Table
-	tab, ok := c.e.metaController.GetSource(c.SchemaName(), ast.TableName)
+	tab, ok := c.e.metaController.GetSource(c.SchemaName(), ast.TableName.Name())
 	if !ok {
-		tab, ok = c.e.metaController.GetMaterializedView(c.SchemaName(), ast.TableName)
+		tab, ok = c.e.metaController.GetMaterializedView(c.SchemaName(), ast.TableName.Name())
 		if !ok {
-			return nil, errors.NewUnknownTableError(c.SchemaName(), ast.TableName)
+			return nil, errors.NewUnknownTableError(c.SchemaName(), ast.TableName.Name())
 		}
 	}
 	tabInfo := tab.GetTableInfo()
@@ -198,7 +201,7 @@ func (c *CreateIndexCommand) getIndexInfo(ast *parser.CreateIndex) (*common.Inde
 	if tabInfo.IndexInfos != nil {
 		_, ok := tabInfo.IndexInfos[ast.Name]
 		if ok {
-			return nil, errors.NewIndexAlreadyExistsError(c.SchemaName(), ast.TableName, ast.Name)
+			return nil, errors.NewIndexAlreadyExistsError(c.SchemaName(), ast.TableName.Name(), ast.Name)
 		}
 	}
 
@@ -212,7 +215,7 @@ func (c *CreateIndexCommand) getIndexInfo(ast *parser.CreateIndex) (*common.Inde
 		colIndex, ok := colMap[colName.Name]
 		if !ok {
 			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Unknown column %s in %s.%s",
-				colName.Name, c.SchemaName(), ast.TableName)
+				colName.Name, c.SchemaName(), ast.TableName.Name())
 		}
 		indexCols[i] = colIndex
 		indexColMap[colIndex] = struct{}{}
@@ -220,7 +223,7 @@ func (c *CreateIndexCommand) getIndexInfo(ast *parser.CreateIndex) (*common.Inde
 	if len(indexColMap) != len(ast.ColumnNames) {
 		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Index cannot contain same column multiple times")
 	}
-	return common.NewIndexInfo(c.SchemaName(), c.tableSequences[0], ast.TableName, ast.Name, indexCols), nil
+	return common.NewIndexInfo(c.SchemaName(), c.tableSequences[0], ast.TableName.Name(), ast.Name, indexCols), nil
 }
 
 func (c *CreateIndexCommand) GetExtraData() []byte {
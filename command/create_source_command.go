@@ -2,7 +2,6 @@ package command
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -251,6 +250,12 @@ func (c *CreateSourceCommand) GetExtraData() []byte {
 // nolint: gocyclo
 func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.SourceInfo, error) {
 	ast.Name = strings.ToLower(ast.Name)
+	if err := ast.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if ast.Query != nil {
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Derived sources are not supported")
+	}
 	var (
 		colNames []string
 		colTypes []common.ColumnType
@@ -263,12 +268,23 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 			// Convert AST column definition to a ColumnType.
 			col := option.Column
 			cName := strings.ToLower(col.Name)
+			if col.Generated != nil {
+				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Generated columns are not supported")
+			}
+			if col.AutoIncrement {
+				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "AUTO_INCREMENT columns are not supported")
+			}
 			colIndex[cName] = i
 			colNames = append(colNames, cName)
 			colType, err := col.ToColumnType()
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
+			switch colType.Type {
+			case common.TypeDate, common.TypeTime, common.TypeTimestampTZ, common.TypeArray, common.TypeMap, common.TypeStruct:
+				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Column type %s is not supported", colType.String())
+			default:
+			}
 			colTypes = append(colTypes, colType)
 		case len(option.PrimaryKey) > 0:
 			for _, pk := range option.PrimaryKey {
@@ -295,22 +311,18 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 		sRetentionTime                             string
 	)
 	for _, opt := range ast.OriginInformation {
+		for _, enc := range []string{opt.HeaderEncoding, opt.KeyEncoding, opt.ValueEncoding} {
+			if parser.EncodingName(enc) == parser.EncodingAvro {
+				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Avro encoding is not supported")
+			}
+		}
 		switch {
 		case opt.HeaderEncoding != "":
 			headerEncoding = common.KafkaEncodingFromString(opt.HeaderEncoding)
-			if headerEncoding.Encoding == common.EncodingUnknown {
-				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Unknown topic encoding %s", opt.HeaderEncoding)
-			}
 		case opt.KeyEncoding != "":
 			keyEncoding = common.KafkaEncodingFromString(opt.KeyEncoding)
-			if keyEncoding.Encoding == common.EncodingUnknown {
-				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Unknown topic encoding %s", opt.KeyEncoding)
-			}
 		case opt.ValueEncoding != "":
 			valueEncoding = common.KafkaEncodingFromString(opt.ValueEncoding)
-			if valueEncoding.Encoding == common.EncodingUnknown {
-				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Unknown topic encoding %s", opt.ValueEncoding)
-			}
 		case opt.IngestFilter != "":
 			ingestFilter = opt.IngestFilter
 		case opt.Properties != nil:
@@ -322,16 +334,34 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 			cs := opt.ColSelectors
 			colSelectors = make([]selector.ColumnSelector, len(cs))
 			for i := 0; i < len(cs); i++ {
+				if cs[i].Cast != nil {
+					return nil, errors.NewPranaErrorf(errors.InvalidStatement, "CAST in column selectors is not supported")
+				}
+				if cs[i].Position != nil {
+					return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Positional column selectors are not supported")
+				}
 				colSelectors[i] = cs[i].ToSelector()
 			}
 		case opt.BrokerName != "":
 			brokerName = opt.BrokerName
-		case opt.TopicName != "":
-			topicName = opt.TopicName
+		case len(opt.TopicName) > 0:
+			if len(opt.TopicName) > 1 {
+				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Sources consuming from multiple topics are not supported")
+			}
+			topicName = opt.TopicName[0]
 		case opt.InitialState != "":
 			initialiseFrom = opt.InitialState
 		case opt.RetentionTime != "":
 			sRetentionTime = opt.RetentionTime
+		case opt.CompactionPriority != "":
+			if strings.ToLower(opt.CompactionPriority) != parser.CompactionPriorityNormal {
+				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "CompactionPriority = '%s' is not supported",
+					opt.CompactionPriority)
+			}
+		case opt.OnError != "":
+			if strings.ToLower(opt.OnError) != parser.OnErrorFail {
+				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "OnError = '%s' is not supported", opt.OnError)
+			}
 		}
 		if opt.Transient != nil && *opt.Transient {
 			transient = true
@@ -371,7 +401,7 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 	var retentionTime time.Duration
 	if sRetentionTime != "" {
 		var err error
-		retentionTime, err = parseRetentionTime(sRetentionTime)
+		retentionTime, err = parser.ParseRetentionTime(sRetentionTime)
 		if err != nil {
 			return nil, err
 		}
@@ -417,25 +447,3 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 		OriginInfo: originInfo,
 	}, nil
 }
-
-func parseRetentionTime(retentionTime string) (time.Duration, error) {
-	sr := strings.Trim(retentionTime, " \t")
-	var dur time.Duration
-	l := len(sr)
-	if l > 1 {
-		if sr[l-1] == 'd' {
-			numDays, err := strconv.Atoi(sr[:l-1])
-			if err == nil {
-				dur = time.Duration(numDays) * time.Hour * 24
-			}
-		} else if !strings.HasSuffix(sr, "ms") && !strings.HasSuffix(sr, "us") && !strings.HasSuffix(sr, "µs") &&
-			!strings.HasSuffix(sr, "ns") {
-			dur, _ = time.ParseDuration(sr)
-		}
-	}
-	if dur <= 0 {
-		return 0, errors.NewPranaErrorf(errors.InvalidStatement, "Invalid RetentionTime %s. Must be an integer > 0 "+
-			"followed by a unit. Valid units are \"d\", \"s\", \"m\", \"h\"", retentionTime)
-	}
-	return dur, nil
-}
@@ -148,7 +148,7 @@ func (d *DropIndexCommand) getIndexInfo() (*common.IndexInfo, error) {
 			return nil, errors.Errorf("not a drop index command %s", d.sql)
 		}
 		d.indexName = strings.ToLower(ast.Drop.Name)
-		d.tableName = strings.ToLower(ast.Drop.TableName)
+		d.tableName = strings.ToLower(ast.Drop.TableName.Name())
 	}
 	if d.tableName == "" {
 		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Drop index requires a table")
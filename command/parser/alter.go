@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+)
+
+// CheckAlterCompatibility checks that a source defined by old can be redefined as next without invalidating data that
+// has already been ingested, or the materialized views that read from it.
+//
+// A redefinition is incompatible if it changes the primary key, removes a column that is referenced, changes a column to
+// a type that cannot hold all of its existing values, or changes the key encoding. Leaving out the key encoding keeps the
+// existing one.
+//
+// referenced holds the lower case names of the columns read by materialized views. Other columns may be removed, as
+// nothing downstream reads them.
+func CheckAlterCompatibility(old *CreateSource, next *CreateSource, referenced []string) error {
+	_, oldPK, err := old.columns()
+	if err != nil {
+		return err
+	}
+	nextCols, nextPK, err := next.columns()
+	if err != nil {
+		return err
+	}
+	for _, pk := range oldPK {
+		found := false
+		for _, nextPk := range nextPK {
+			if pk == nextPk {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.NewPranaErrorf(errors.InvalidStatement, "Cannot alter source %s, primary key column %s cannot be removed",
+				next.Name, pk)
+		}
+	}
+	if strings.Join(oldPK, ", ") != strings.Join(nextPK, ", ") {
+		return errors.NewPranaErrorf(errors.InvalidStatement, "Cannot alter source %s, primary key cannot be changed from (%s) to (%s)",
+			next.Name, strings.Join(oldPK, ", "), strings.Join(nextPK, ", "))
+	}
+	// Check the columns in the order they're declared so the error reported for a statement is always the same.
+	for _, opt := range old.Options {
+		if opt.Column == nil {
+			continue
+		}
+		name := strings.ToLower(opt.Column.Name)
+		nextType, ok := nextCols[name]
+		if !ok {
+			for _, ref := range referenced {
+				if ref == name {
+					return errors.NewPranaErrorf(errors.InvalidStatement,
+						"Cannot alter source %s, column %s is used by a materialized view and cannot be removed", next.Name, name)
+				}
+			}
+			continue
+		}
+		oldType, err := opt.Column.ToColumnType()
+		if err != nil {
+			return err
+		}
+		if !isWidening(oldType, nextType) {
+			return errors.NewPranaErrorf(errors.InvalidStatement, "Cannot alter source %s, column %s cannot be changed from %s to %s",
+				next.Name, name, oldType.String(), nextType.String())
+		}
+	}
+	oldEnc, nextEnc := old.keyEncoding(), next.keyEncoding()
+	if nextEnc != "" && oldEnc != nextEnc {
+		return errors.NewPranaErrorf(errors.InvalidStatement, "Cannot alter source %s, KeyEncoding cannot be changed", next.Name)
+	}
+	return nil
+}
+
+// columns returns the column types of the source by lower case name, and the lower case primary key column names.
+func (c *CreateSource) columns() (map[string]common.ColumnType, []string, error) {
+	cols := make(map[string]common.ColumnType, len(c.Options))
+	var pk []string
+	for _, opt := range c.Options {
+		switch {
+		case opt.Column != nil:
+			ct, err := opt.Column.ToColumnType()
+			if err != nil {
+				return nil, nil, err
+			}
+			cols[strings.ToLower(opt.Column.Name)] = ct
+		case len(opt.PrimaryKey) > 0:
+			for _, name := range opt.PrimaryKey {
+				pk = append(pk, strings.ToLower(name))
+			}
+		}
+	}
+	return cols, pk, nil
+}
+
+// keyEncoding returns the KeyEncoding option with the encoding in lower case, e.g. "protobuf:foo.bar.MyMessage" for
+// "Protobuf:foo.bar.MyMessage", or "" if it is not set.
+func (c *CreateSource) keyEncoding() string {
+	for _, opt := range c.OriginInformation {
+		if opt.KeyEncoding != "" {
+			name, schema := splitEncoding(opt.KeyEncoding)
+			if schema == "" {
+				return name
+			}
+			return name + ":" + schema
+		}
+	}
+	return ""
+}
+
+// isWidening returns true if every value of type from can be represented by type to.
+func isWidening(from common.ColumnType, to common.ColumnType) bool {
+	switch from.Type {
+	case common.TypeTinyInt:
+		return to.Type == common.TypeTinyInt || to.Type == common.TypeInt || to.Type == common.TypeBigInt
+	case common.TypeInt:
+		return to.Type == common.TypeInt || to.Type == common.TypeBigInt
+	case common.TypeDecimal:
+		return to.Type == common.TypeDecimal && to.DecScale >= from.DecScale &&
+			to.DecPrecision-to.DecScale >= from.DecPrecision-from.DecScale
+	case common.TypeTimestamp, common.TypeTime, common.TypeTimestampTZ:
+		return to.Type == from.Type && to.FSP >= from.FSP
+	case common.TypeArray, common.TypeMap, common.TypeStruct:
+		return sameColumnType(from, to)
+	default:
+		return to.Type == from.Type
+	}
+}
+
+// sameColumnType returns true if a and b are the same type, comparing the element types of complex types rather than
+// the pointers that hold them.
+func sameColumnType(a common.ColumnType, b common.ColumnType) bool {
+	if a.Complex == nil || b.Complex == nil {
+		return a == b
+	}
+	aElems, bElems := a.Complex.Elements, b.Complex.Elements
+	if a.Type != b.Type || len(aElems) != len(bElems) {
+		return false
+	}
+	for i := range aElems {
+		if aElems[i].Name != bElems[i].Name || !sameColumnType(aElems[i].ColumnType, bElems[i].ColumnType) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAlterCompatibility(t *testing.T) {
+	old := `CREATE SOURCE s (id int, name varchar, price decimal(10, 2), primary key (id)) WITH (KeyEncoding = "json")`
+	tests := []struct {
+		name       string
+		next       string
+		referenced []string
+		err        string
+	}{
+		{name: "unchanged", next: old},
+		{name: "widening",
+			next: `CREATE SOURCE s (id bigint, name varchar, price decimal(12, 3), added double, primary key (id)) WITH (KeyEncoding = "JSON")`},
+		{name: "primary key removed",
+			next: `CREATE SOURCE s (id int, name varchar, price decimal(10, 2), primary key (name)) WITH (KeyEncoding = "json")`,
+			err:  "PDB1000 - Cannot alter source s, primary key column id cannot be removed"},
+		{name: "primary key column added",
+			next: `CREATE SOURCE s (id int, name varchar, price decimal(10, 2), primary key (id, name)) WITH (KeyEncoding = "json")`,
+			err:  "PDB1000 - Cannot alter source s, primary key cannot be changed from (id) to (id, name)"},
+		{name: "narrowing",
+			next: `CREATE SOURCE s (id int, name varchar, price decimal(10, 4), primary key (id)) WITH (KeyEncoding = "json")`,
+			err:  "PDB1000 - Cannot alter source s, column price cannot be changed from decimal(10, 2) to decimal(10, 4)"},
+		{name: "incompatible type",
+			next: `CREATE SOURCE s (id int, name bigint, price decimal(10, 2), primary key (id)) WITH (KeyEncoding = "json")`,
+			err:  "PDB1000 - Cannot alter source s, column name cannot be changed from varchar to bigint"},
+		{name: "unreferenced column removed",
+			next:       `CREATE SOURCE s (id int, price decimal(10, 2), primary key (id)) WITH (KeyEncoding = "json")`,
+			referenced: []string{"id", "price"}},
+		{name: "referenced column removed",
+			next:       `CREATE SOURCE s (id int, price decimal(10, 2), primary key (id)) WITH (KeyEncoding = "json")`,
+			referenced: []string{"name"},
+			err:        "PDB1000 - Cannot alter source s, column name is used by a materialized view and cannot be removed"},
+		{name: "key encoding omitted",
+			next: `CREATE SOURCE s (id int, name varchar, price decimal(10, 2), primary key (id)) WITH (TopicName = "t")`},
+		{name: "key encoding changed",
+			next: `CREATE SOURCE s (id int, name varchar, price decimal(10, 2), primary key (id)) WITH (KeyEncoding = "int32be")`,
+			err:  "PDB1000 - Cannot alter source s, KeyEncoding cannot be changed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldAST, err := Parse(old)
+			require.NoError(t, err)
+			nextAST, err := Parse(test.next)
+			require.NoError(t, err)
+			err = CheckAlterCompatibility(oldAST.Create.Source, nextAST.Create.Source, test.referenced)
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestCheckAlterCompatibilityComplexTypes(t *testing.T) {
+	old := `CREATE SOURCE s (id int, tags ARRAY<VARCHAR>, readings STRUCT<at: TIMESTAMP(3), value: DECIMAL(10, 2)>, primary key (id)) WITH (KeyEncoding = "json")`
+	tests := []struct {
+		name string
+		next string
+		err  string
+	}{
+		{name: "unchanged", next: old},
+		{name: "element type changed",
+			next: `CREATE SOURCE s (id int, tags ARRAY<BIGINT>, readings STRUCT<at: TIMESTAMP(3), value: DECIMAL(10, 2)>, primary key (id)) WITH (KeyEncoding = "json")`,
+			err:  "PDB1000 - Cannot alter source s, column tags cannot be changed from array<varchar> to array<bigint>"},
+		{name: "field renamed",
+			next: `CREATE SOURCE s (id int, tags ARRAY<VARCHAR>, readings STRUCT<ts: TIMESTAMP(3), value: DECIMAL(10, 2)>, primary key (id)) WITH (KeyEncoding = "json")`,
+			err:  "PDB1000 - Cannot alter source s, column readings cannot be changed from struct<at: timestamp(3), value: decimal(10, 2)> to struct<ts: timestamp(3), value: decimal(10, 2)>"},
+		{name: "nested parameters changed",
+			next: `CREATE SOURCE s (id int, tags ARRAY<VARCHAR>, readings STRUCT<at: TIMESTAMP(3), value: DECIMAL(12, 2)>, primary key (id)) WITH (KeyEncoding = "json")`,
+			err:  "PDB1000 - Cannot alter source s, column readings cannot be changed from struct<at: timestamp(3), value: decimal(10, 2)> to struct<at: timestamp(3), value: decimal(12, 2)>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldAST, err := Parse(old)
+			require.NoError(t, err)
+			nextAST, err := Parse(test.next)
+			require.NoError(t, err)
+			err = CheckAlterCompatibility(oldAST.Create.Source, nextAST.Create.Source, nil)
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestCheckAlterCompatibilityKey(t *testing.T) {
+	old := `CREATE SOURCE s (a int, b int, primary key (a, b)) WITH (KeyEncoding = "Protobuf:foo.bar.Key")`
+	tests := []struct {
+		name string
+		next string
+		err  string
+	}{
+		{name: "encoding case",
+			next: `CREATE SOURCE s (a int, b int, primary key (A, B)) WITH (KeyEncoding = "protobuf:foo.bar.Key")`},
+		{name: "primary key reordered",
+			next: `CREATE SOURCE s (a int, b int, primary key (b, a)) WITH (KeyEncoding = "Protobuf:foo.bar.Key")`,
+			err:  "PDB1000 - Cannot alter source s, primary key cannot be changed from (a, b) to (b, a)"},
+		{name: "key message changed",
+			next: `CREATE SOURCE s (a int, b int, primary key (a, b)) WITH (KeyEncoding = "Protobuf:foo.bar.OtherKey")`,
+			err:  "PDB1000 - Cannot alter source s, KeyEncoding cannot be changed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldAST, err := Parse(old)
+			require.NoError(t, err)
+			nextAST, err := Parse(test.next)
+			require.NoError(t, err)
+			err = CheckAlterCompatibility(oldAST.Create.Source, nextAST.Create.Source, nil)
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
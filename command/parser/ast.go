@@ -6,6 +6,7 @@ package parser
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/squareup/pranadb/command/parser/selector"
 	"github.com/squareup/pranadb/errors"
@@ -20,9 +21,12 @@ import (
 const DefaultFSP = 0
 
 // RawQuery represents raw SQL that can be passed through directly.
+//
+// It runs up to the first top level ";". String literals are matched explicitly because once unquoted a literal like
+// ';' has the same value as the terminator.
 type RawQuery struct {
 	Tokens []lexer.Token
-	Query  []string `(!";")+`
+	Query  []string `(String | !";")+`
 }
 
 func (r *RawQuery) String() string {
@@ -38,14 +42,72 @@ func (r *RawQuery) String() string {
 	return out.String()
 }
 
+// Params returns the bind parameters in the query, in the order they appear.
+func (r *RawQuery) Params() []*Param {
+	return paramsFromTokens(r.Tokens)
+}
+
 // CreateMaterializedView statement.
 type CreateMaterializedView struct {
+	Pos lexer.Position
+
 	Name              string                               `@Ident`
 	OriginInformation []*MaterializedViewOriginInformation `("WITH" "(" @@ ("," @@)* ")")?`
+	Window            *Window                              `("WINDOW" @@)?`
+	Emit              string                               `("EMIT" @("CHANGES" | "FINAL"))?`
 	Query             *RawQuery                            `"AS" @@`
 }
 
+const (
+	WindowTumbling = "tumbling"
+	WindowHopping  = "hopping"
+
+	EmitChanges = "changes"
+	EmitFinal   = "final"
+)
+
+// Window specification of a materialized view, e.g. WINDOW HOPPING (SIZE 1 HOUR, ADVANCE 10 MINUTES, GRACE 1 MINUTE).
+type Window struct {
+	Pos lexer.Position
+
+	Type    string    `@("TUMBLING" | "HOPPING")`
+	Size    *Duration `"(" "SIZE" @@`
+	Advance *Duration `("," "ADVANCE" "BY"? @@)?`
+	Grace   *Duration `("," "GRACE" "PERIOD"? @@)? ")"`
+}
+
+// Duration is a length of time written as a count and a unit, e.g. 10 SECONDS.
+type Duration struct {
+	Value int64  `@Number`
+	Unit  string `@("MILLISECOND" | "MILLISECONDS" | "SECOND" | "SECONDS" | "MINUTE" | "MINUTES" | "HOUR" | "HOURS" | "DAY" | "DAYS")`
+}
+
+// Duration returns d as a time.Duration, or 0 if d is nil.
+func (d *Duration) Duration() time.Duration {
+	if d == nil {
+		return 0
+	}
+	var unit time.Duration
+	switch strings.TrimSuffix(strings.ToUpper(d.Unit), "S") {
+	case "MILLISECOND":
+		unit = time.Millisecond
+	case "SECOND":
+		unit = time.Second
+	case "MINUTE":
+		unit = time.Minute
+	case "HOUR":
+		unit = time.Hour
+	case "DAY":
+		unit = 24 * time.Hour
+	default:
+		panic(d.Unit) // The grammar only accepts the units above.
+	}
+	return time.Duration(d.Value) * unit
+}
+
 type MaterializedViewOriginInformation struct {
+	Pos lexer.Position
+
 	InitialState string `"InitialState" "=" @String`
 }
 
@@ -54,46 +116,139 @@ type ColumnDef struct {
 
 	Name string `@Ident`
 
-	Type       common.Type `@(("VARCHAR"|"TINYINT"|"INT"|"BIGINT"|"TIMESTAMP"|"DOUBLE"|"DECIMAL"))` // Conversion done by common.Type.Capture()
-	Parameters []int       `("(" @Number ("," @Number)* ")")?`                                      // Optional parameters to the type(x [, x, ...])
+	Type          common.Type    `@(("VARCHAR"|"TINYINT"|"INT"|"BIGINT"|"TIMESTAMP"|"DOUBLE"|"DECIMAL"|"TEXT"|"STRING"|"FLOAT"|"INTEGER"|"DATE"|"TIME"|"ARRAY"|"MAP"|"STRUCT"))` // Conversion done by common.Type.Capture()
+	Elements      []*ElementType `("<" @@ ("," @@)* ">")?`                                                                                                                       // Element types of ARRAY<x>, MAP<k, v> and STRUCT<name: x, ...>
+	Parameters    []int          `("(" @Number ("," @Number)* ")")?`                                                                                                             // Optional parameters to the type(x [, x, ...])
+	WithTimeZone  bool           `@("WITH" "TIME" "ZONE")?`                                                                                                                      // Only valid for TIMESTAMP
+	AutoIncrement bool           `@("AUTO_INCREMENT" | "GENERATED" "ALWAYS" "AS" "IDENTITY")?`                                                                                   // Values are assigned by the server
+	Generated     *Expression    `("AS" "(" @@ ")")?`                                                                                                                            // Expression computing a generated column, e.g. total AS (price * qty)
+}
+
+// ElementType is the type of an element of a complex column type. Elements can be complex types themselves, e.g.
+// ARRAY<ARRAY<INT>>.
+type ElementType struct {
+	Pos lexer.Position
+
+	Name         string         `(@Ident ":")?` // Only valid for STRUCT fields
+	Type         common.Type    `@(("VARCHAR"|"TINYINT"|"INT"|"BIGINT"|"TIMESTAMP"|"DOUBLE"|"DECIMAL"|"TEXT"|"STRING"|"FLOAT"|"INTEGER"|"DATE"|"TIME"|"ARRAY"|"MAP"|"STRUCT"))`
+	Elements     []*ElementType `("<" @@ ("," @@)* ">")?`
+	Parameters   []int          `("(" @Number ("," @Number)* ")")?`
+	WithTimeZone bool           `@("WITH" "TIME" "ZONE")?`
 }
 
 func (c *ColumnDef) ToColumnType() (common.ColumnType, error) {
-	ct, ok := common.ColumnTypesByType[c.Type]
+	return toColumnType(c.Pos, c.Type, c.Elements, c.Parameters, c.WithTimeZone)
+}
+
+func toColumnType(pos lexer.Position, typ common.Type, elements []*ElementType, params []int, withTimeZone bool) (common.ColumnType, error) {
+	if withTimeZone && typ != common.TypeTimestamp {
+		return common.ColumnType{}, participle.Errorf(pos, "WITH TIME ZONE is only valid for TIMESTAMP")
+	}
+	if len(elements) != 0 && typ != common.TypeArray && typ != common.TypeMap && typ != common.TypeStruct {
+		return common.ColumnType{}, participle.Errorf(pos, "%s does not take element types", typ)
+	}
+	ct, ok := common.ColumnTypesByType[typ]
 	if ok {
-		if len(c.Parameters) != 0 {
-			return common.ColumnType{}, errors.WithStack(participle.Errorf(c.Pos, ""))
+		if len(params) != 0 {
+			return common.ColumnType{}, errors.WithStack(participle.Errorf(pos, "%s does not take parameters", typ))
 		}
 		return ct, nil
 	}
-	switch c.Type {
+	switch typ {
 	case common.TypeDecimal:
-		if len(c.Parameters) != 2 {
-			return common.ColumnType{}, participle.Errorf(c.Pos, "Expected DECIMAL(precision, scale)")
+		if len(params) != 2 {
+			return common.ColumnType{}, participle.Errorf(pos, "Expected DECIMAL(precision, scale)")
 		}
-		prec := c.Parameters[0]
-		scale := c.Parameters[1]
+		prec := params[0]
+		scale := params[1]
 		if prec > 65 || prec < 1 {
-			return common.ColumnType{}, participle.Errorf(c.Pos, "Decimal precision must be > 0 and <= 65")
+			return common.ColumnType{}, participle.Errorf(pos, "Decimal precision must be > 0 and <= 65")
 		}
 		if scale > 30 || scale < 0 {
-			return common.ColumnType{}, participle.Errorf(c.Pos, "decimal scale must be >= 0 and <= 30")
+			return common.ColumnType{}, participle.Errorf(pos, "decimal scale must be >= 0 and <= 30")
 		}
 		if scale > prec {
-			return common.ColumnType{}, participle.Errorf(c.Pos, "Decimal scale must be <= precision")
+			return common.ColumnType{}, participle.Errorf(pos, "Decimal scale must be <= precision")
 		}
-		return common.NewDecimalColumnType(c.Parameters[0], c.Parameters[1]), nil
+		return common.NewDecimalColumnType(params[0], params[1]), nil
 	case common.TypeTimestamp:
 		var fsp int8 = DefaultFSP
-		if len(c.Parameters) == 1 {
-			fsp = int8(c.Parameters[0])
-			if fsp < 0 || fsp > 6 {
-				return common.ColumnType{}, participle.Errorf(c.Pos, "Timestamp fsp must be >= 0 and <= 6")
+		if len(params) > 1 {
+			return common.ColumnType{}, participle.Errorf(pos, "Expected TIMESTAMP or TIMESTAMP(fsp)")
+		}
+		if len(params) == 1 {
+			// Check the range before narrowing, so that e.g. 300 isn't wrapped into range.
+			if params[0] < 0 || params[0] > 6 {
+				return common.ColumnType{}, participle.Errorf(pos, "Timestamp fsp must be >= 0 and <= 6")
 			}
+			fsp = int8(params[0])
+		}
+		if withTimeZone {
+			return common.NewTimestampTZColumnType(fsp), nil
 		}
 		return common.NewTimestampColumnType(fsp), nil
+	case common.TypeTime:
+		var fsp int8 = DefaultFSP
+		if len(params) > 1 {
+			return common.ColumnType{}, participle.Errorf(pos, "Expected TIME or TIME(fsp)")
+		}
+		if len(params) == 1 {
+			if params[0] < 0 || params[0] > 6 {
+				return common.ColumnType{}, participle.Errorf(pos, "Time fsp must be >= 0 and <= 6")
+			}
+			fsp = int8(params[0])
+		}
+		return common.NewTimeColumnType(fsp), nil
+	case common.TypeArray, common.TypeMap, common.TypeStruct:
+		if len(params) != 0 {
+			return common.ColumnType{}, participle.Errorf(pos, "%s does not take parameters", typ)
+		}
+		return toComplexColumnType(pos, typ, elements)
+	default:
+		panic(typ) // If this happens there's something wrong with the parser and/or validation.
+	}
+}
+
+func toComplexColumnType(pos lexer.Position, typ common.Type, elements []*ElementType) (common.ColumnType, error) {
+	elementTypes := make([]common.ColumnInfo, len(elements))
+	names := make(map[string]struct{}, len(elements))
+	for i, element := range elements {
+		switch {
+		case typ == common.TypeStruct && element.Name == "":
+			return common.ColumnType{}, participle.Errorf(element.Pos, "STRUCT fields must be named, e.g. STRUCT<name: VARCHAR>")
+		case typ != common.TypeStruct && element.Name != "":
+			return common.ColumnType{}, participle.Errorf(element.Pos, "Only STRUCT fields can be named")
+		}
+		name := strings.ToLower(element.Name)
+		if _, ok := names[name]; ok && name != "" {
+			return common.ColumnType{}, participle.Errorf(element.Pos, "Duplicate STRUCT field %s", name)
+		}
+		names[name] = struct{}{}
+		ct, err := toColumnType(element.Pos, element.Type, element.Elements, element.Parameters, element.WithTimeZone)
+		if err != nil {
+			return common.ColumnType{}, err
+		}
+		elementTypes[i] = common.ColumnInfo{Name: name, ColumnType: ct}
+	}
+	switch typ {
+	case common.TypeArray:
+		if len(elementTypes) != 1 {
+			return common.ColumnType{}, participle.Errorf(pos, "Expected ARRAY<element type>")
+		}
+		return common.NewArrayColumnType(elementTypes[0].ColumnType), nil
+	case common.TypeMap:
+		if len(elementTypes) != 2 {
+			return common.ColumnType{}, participle.Errorf(pos, "Expected MAP<key type, value type>")
+		}
+		if elementTypes[0].Complex != nil {
+			return common.ColumnType{}, participle.Errorf(elements[0].Pos, "MAP key type must not be a complex type")
+		}
+		return common.NewMapColumnType(elementTypes[0].ColumnType, elementTypes[1].ColumnType), nil
 	default:
-		panic(c.Type) // If this happens there's something wrong with the parser and/or validation.
+		if len(elementTypes) == 0 {
+			return common.ColumnType{}, participle.Errorf(pos, "Expected STRUCT<name: type, ...>")
+		}
+		return common.NewStructColumnType(elementTypes), nil
 	}
 }
 
@@ -102,34 +257,80 @@ type TableOption struct {
 	Column     *ColumnDef `| @@`
 }
 
+// CreateSource statement. A source either ingests from a topic, described by the WITH options, or is derived from a
+// query over other sources given with AS. Validate checks exactly one of the two is present.
 type CreateSource struct {
+	Pos lexer.Position
+
 	Name              string                     `@Ident`
 	Options           []*TableOption             `"(" @@ ("," @@)* ")"` // Table options.
-	OriginInformation []*SourceOriginInformation `"WITH" "(" @@ ("," @@)* ")"`
+	OriginInformation []*SourceOriginInformation `("WITH" "(" @@ ("," @@)* ")")?`
+	Query             *RawQuery                  `("AS" @@)?` // Query for a derived source.
 }
 
 type CreateSink struct {
+	Pos lexer.Position
+
 	Name              string                   `@Ident`
 	TargetInformation []*SinkTargetInformation `("WITH" "(" @@ ("," @@)* ")")?`
 	Query             *RawQuery                `"AS" @@`
 }
 
 type SourceOriginInformation struct {
-	BrokerName       string                        `"BrokerName" "=" @String`
-	TopicName        string                        `|"TopicName" "=" @String`
-	HeaderEncoding   string                        `|"HeaderEncoding" "=" @String`
-	KeyEncoding      string                        `|"KeyEncoding" "=" @String`
-	ValueEncoding    string                        `|"ValueEncoding" "=" @String`
-	IngestFilter     string                        `|"IngestFilter" "=" @String`
-	InitialState     string                        `|"InitialState" "=" @String`
-	Transient        *Boolean                      `|"Transient" "=" @Ident`
-	StartWithFirstMV *Boolean                      `|"StartWithFirstMV" "=" @Ident`
-	RetentionTime    string                        `|"RetentionTime" "=" @String`
-	ColSelectors     []*selector.ColumnSelectorAST `|"ColumnSelectors" "=" "(" (@@ ("," @@)*)? ")"`
-	Properties       []*TopicInfoProperty          `|"Properties" "=" "(" (@@ ("," @@)*)? ")"`
+	Pos lexer.Position
+
+	BrokerName         string                        `"BrokerName" "=" @String`
+	TopicName          []string                      `|"TopicName" "=" (@String | "(" @String ("," @String)* ")")`
+	HeaderEncoding     string                        `|"HeaderEncoding" "=" @String`
+	KeyEncoding        string                        `|"KeyEncoding" "=" @String`
+	ValueEncoding      string                        `|"ValueEncoding" "=" @String`
+	IngestFilter       string                        `|"IngestFilter" "=" @String`
+	InitialState       string                        `|"InitialState" "=" @String`
+	Transient          *Boolean                      `|"Transient" "=" @Ident`
+	StartWithFirstMV   *Boolean                      `|"StartWithFirstMV" "=" @Ident`
+	RetentionTime      string                        `|"RetentionTime" "=" @String`
+	CompactionPriority string                        `|"CompactionPriority" "=" @String` // One of the CompactionPriority* constants.
+	DeadLetterTopic    string                        `|"DeadLetterTopic" "=" @String`
+	OnError            string                        `|"OnError" "=" @String`      // One of the OnError* constants.
+	HasColSelectors    bool                          `|@"ColumnSelectors" "=" "("` // Set even if the list is empty.
+	ColSelectors       []*selector.ColumnSelectorAST `  (@@ ("," @@)*)? ")"`
+	HasProperties      bool                          `|@"Properties" "=" "("` // Set even if the list is empty.
+	Properties         []*TopicInfoProperty          `  (@@ ("," @@)*)? ")"`
 }
 
+// Values of SourceOriginInformation.OnError, which determines what happens to a message that cannot be ingested.
+const (
+	OnErrorFail       = "fail"       // Fail the batch and retry it, the default.
+	OnErrorSkip       = "skip"       // Drop the message.
+	OnErrorDeadLetter = "deadletter" // Forward the message to the DeadLetterTopic.
+)
+
+// Encodings of SourceOriginInformation.HeaderEncoding, KeyEncoding and ValueEncoding, which are written as
+// "<encoding>[:<schema name>]" and matched case insensitively.
+const (
+	EncodingJSON        = "json"
+	EncodingProtobuf    = "protobuf" // Requires a message type, e.g. "protobuf:foo.bar.MyMessage".
+	EncodingAvro        = "avro"     // Requires a schema name, e.g. "avro:my_schema".
+	EncodingRaw         = "raw"
+	EncodingCSV         = "csv"
+	EncodingStringBytes = "stringbytes"
+	EncodingFloat32BE   = "float32be"
+	EncodingFloat64BE   = "float64be"
+	EncodingInt16BE     = "int16be"
+	EncodingInt32BE     = "int32be"
+	EncodingInt64BE     = "int64be"
+)
+
+// Values of SourceOriginInformation.CompactionPriority, which determines how aggressively the source's data is compacted.
+const (
+	CompactionPriorityLow    = "low"    // Compact only when the source is otherwise idle.
+	CompactionPriorityNormal = "normal" // The default.
+	CompactionPriorityHigh   = "high"   // Compact ahead of other sources, to reduce read amplification.
+)
+
 type SinkTargetInformation struct {
+	Pos lexer.Position
+
 	BrokerName          string                        `"BrokerName" "=" @String`
 	TopicName           string                        `|"TopicName" "=" @String`
 	NumPartitions       int                           `|"NumPartitions" "=" @Number`
@@ -162,22 +363,54 @@ type ColSelector struct {
 }
 
 type TopicInfoProperty struct {
+	Pos lexer.Position
+
 	Key   string `@String "="`
 	Value string `@String`
 }
 
 type CreateIndex struct {
+	Pos lexer.Position
+
 	Name        string        `@Ident "ON"`
-	TableName   string        `@Ident`
+	TableName   *Ref          `@@`
 	ColumnNames []*ColumnName `"(" @@ ("," @@)* ")"`
+	Filter      *Expression   `("WHERE" @@)?` // Optional predicate restricting the index to matching rows.
 }
 
 type ColumnName struct {
+	Pos lexer.Position
+
 	Name string `@Ident`
 }
 
+// Ref is a reference to a table, optionally qualified by its schema, e.g. "t" or "s.t".
+type Ref struct {
+	Pos lexer.Position
+
+	Parts []string `@Ident ("." @Ident)?`
+}
+
+// Schema returns the schema qualifier of the reference, or defaultSchema if it is unqualified.
+func (r *Ref) Schema(defaultSchema string) string {
+	if r == nil || len(r.Parts) < 2 {
+		return defaultSchema
+	}
+	return r.Parts[0]
+}
+
+// Name returns the unqualified table name, or "" if there is no reference.
+func (r *Ref) Name() string {
+	if r == nil || len(r.Parts) == 0 {
+		return ""
+	}
+	return r.Parts[len(r.Parts)-1]
+}
+
 // Create statement.
 type Create struct {
+	Pos lexer.Position
+
 	MaterializedView *CreateMaterializedView `  "MATERIALIZED" "VIEW" @@`
 	Source           *CreateSource           `| "SOURCE" @@`
 	Sink             *CreateSink             `| "SINK" @@`
@@ -186,35 +419,113 @@ type Create struct {
 
 // Drop statement
 type Drop struct {
+	Pos lexer.Position
+
 	MaterializedView bool   `(   @"MATERIALIZED" "VIEW"`
 	Source           bool   `  | @"SOURCE"`
 	Sink             bool   `  | @"SINK"`
 	Index            bool   `  | @"INDEX" )`
 	Name             string `@Ident `
-	TableName        string `("ON" @Ident)?`
+	TableName        *Ref   `("ON" @@)?`
 }
 
 // Show statement
 type Show struct {
-	Tables    bool   `(  @"TABLES"`
-	Schemas   bool   `| @"SCHEMAS"`
-	Indexes   bool   `| @"INDEXES" )`
-	TableName string `("ON" @Ident)?`
+	Pos lexer.Position
+
+	Tables    bool `(  @"TABLES"`
+	Schemas   bool `| @"SCHEMAS"`
+	Full      bool `| ( @"FULL"?` // Requests detailed SHOW INDEXES output, such as uniqueness and column order.
+	Indexes   bool `    @"INDEXES" )`
+	Stats     bool `| @("STATS" | "ENGINE" "STATUS") )`
+	TableName *Ref `(("ON" | "FROM") @@)?`
 }
 
+// Privilege is a privilege named in a GRANT or REVOKE statement.
+type Privilege struct {
+	Pos lexer.Position
+
+	Name string `(  @("SELECT" | "CREATE" | "DROP")`
+	All  bool   ` | @"ALL" "PRIVILEGES"? )`
+}
+
+// AccessObject is the object a GRANT or REVOKE statement applies to.
+type AccessObject struct {
+	Pos lexer.Position
+
+	MaterializedView bool `(   @"MATERIALIZED" "VIEW"`
+	Source           bool `  | @"SOURCE"`
+	Sink             bool `  | @"SINK"`
+	Schema           bool `  | @"SCHEMA" )`
+	Name             *Ref `@@`
+}
+
+// Grant statement, e.g. GRANT SELECT ON SOURCE s TO alice. Privileges are enforced downstream.
+type Grant struct {
+	Pos lexer.Position
+
+	Privileges []*Privilege  `@@ ("," @@)*`
+	Object     *AccessObject `"ON" @@`
+	Principal  string        `"TO" @(Ident | String)`
+}
+
+// Revoke statement, e.g. REVOKE SELECT ON SOURCE s FROM alice.
+type Revoke struct {
+	Pos lexer.Position
+
+	Privileges []*Privilege  `@@ ("," @@)*`
+	Object     *AccessObject `"ON" @@`
+	Principal  string        `"FROM" @(Ident | String)`
+}
+
+// Explain statement, i.e. EXPLAIN [ANALYZE] <select>. Like a top level SELECT, the query is not parsed here.
+type Explain struct {
+	Analyze bool   // Set to report runtime statistics from executing the query, rather than just its plan.
+	Select  string // Unaltered SELECT statement being explained.
+}
+
+// RebuildIndex statement.
+type RebuildIndex struct {
+	Pos lexer.Position
+
+	Name      string `@Ident "ON"`
+	TableName *Ref   `@@`
+}
+
+// SourceSetMaxRate statement, e.g. SOURCE SET MAX RATE sensor_readings 1000 BURST 5000.
 type SourceSetMaxRate struct {
+	Pos lexer.Position
+
 	SourceName string `@Ident`
 	Rate       int64  `@Number`
+	// Burst is the number of messages that can be ingested at once before the rate applies. It defaults to Rate when
+	// omitted, so is always set once the statement has been parsed.
+	Burst *int64 `("BURST" @Number)?`
+}
+
+// ResetDdl statement, e.g. RESET DDL IF EXISTS my_schema. Resetting an unknown schema other than the one in use is an
+// error unless IF EXISTS is given.
+type ResetDdl struct {
+	Pos lexer.Position
+
+	IfExists bool   `@("IF" "EXISTS")?`
+	Schema   string `@Ident`
 }
 
 // AST root.
 type AST struct {
 	Select           string            // Unaltered SELECT statement, if any.
+	Empty            bool              // Set if the statement contains only whitespace, comments or a lone ';'.
+	Explain          *Explain          // EXPLAIN of a SELECT statement, if any.
+	Parameters       []*Param          // Bind parameters in the statement, in the order they appear.
 	Use              string            `(  "USE" @Ident`
 	Drop             *Drop             ` | "DROP" @@ `
 	Create           *Create           ` | "CREATE" @@ `
 	Show             *Show             ` | "SHOW" @@ `
 	Describe         string            ` | "DESCRIBE" @Ident `
 	SourceSetMaxRate *SourceSetMaxRate ` | "SOURCE" "SET" "MAX" "RATE" @@ `
-	ResetDdl         string            ` | "RESET" "DDL" @Ident ) ';'?`
+	RebuildIndex     *RebuildIndex     ` | "REBUILD" "INDEX" @@ `
+	ResetDdl         *ResetDdl         ` | "RESET" "DDL" @@ `
+	Grant            *Grant            ` | "GRANT" @@ `
+	Revoke           *Revoke           ` | "REVOKE" @@ ) ';'?`
 }
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"testing"
+	"time"
 
 	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/alecthomas/repr"
@@ -21,7 +22,9 @@ func TestParse(t *testing.T) {
 			&AST{Select: "SELECT * FROM table WHERE foo = `bar`"}, ""},
 		{"CreateMV", `CREATE MATERIALIZED VIEW myview AS SELECT * FROM table`, &AST{
 			Create: &Create{
+				Pos: lexer.Position{Offset: 7, Line: 1, Column: 8},
 				MaterializedView: &CreateMaterializedView{
+					Pos:  lexer.Position{Offset: 25, Line: 1, Column: 26},
 					Name: "myview",
 					Query: &RawQuery{
 						Tokens: []lexer.Token{
@@ -62,7 +65,9 @@ func TestParse(t *testing.T) {
 			"prop2" = "val2"
 			)
 		)`, &AST{Create: &Create{
+			Pos: lexer.Position{Offset: 11, Line: 2, Column: 11},
 			Source: &CreateSource{
+				Pos:  lexer.Position{Offset: 18, Line: 2, Column: 18},
 				Name: "sensor_readings",
 				Options: []*TableOption{
 					{Column: &ColumnDef{Pos: lexer.Position{Offset: 38, Line: 3, Column: 4}, Name: "sensor_id", Type: common.Type(3)}},
@@ -71,13 +76,13 @@ func TestParse(t *testing.T) {
 					{PrimaryKey: []string{"sensor_id", "location"}},
 				},
 				OriginInformation: []*SourceOriginInformation{
-					{BrokerName: "testbroker"},
-					{TopicName: "testtopic"},
-					{HeaderEncoding: "json"},
-					{KeyEncoding: "json"},
-					{ValueEncoding: "json"},
-					{IngestFilter: "where sensor_id=1"},
-					{ColSelectors: []*selector.ColumnSelectorAST{
+					{Pos: lexer.Position{Offset: 151, Line: 8, Column: 4}, BrokerName: "testbroker"},
+					{Pos: lexer.Position{Offset: 181, Line: 9, Column: 4}, TopicName: []string{"testtopic"}},
+					{Pos: lexer.Position{Offset: 209, Line: 10, Column: 4}, HeaderEncoding: "json"},
+					{Pos: lexer.Position{Offset: 237, Line: 11, Column: 4}, KeyEncoding: "json"},
+					{Pos: lexer.Position{Offset: 262, Line: 12, Column: 4}, ValueEncoding: "json"},
+					{Pos: lexer.Position{Offset: 298, Line: 13, Column: 13}, IngestFilter: "where sensor_id=1"},
+					{Pos: lexer.Position{Offset: 337, Line: 14, Column: 4}, HasColSelectors: true, ColSelectors: []*selector.ColumnSelectorAST{
 						{MetaKey: stringRef("key"), Next: &selector.SelectorAST{Field: "k0"}},
 						{Field: stringRef("v1")},
 						{Field: stringRef("v2")},
@@ -86,20 +91,20 @@ func TestParse(t *testing.T) {
 						},
 					},
 					},
-					{Properties: []*TopicInfoProperty{
-						{Key: "prop1", Value: "val1"},
-						{Key: "prop2", Value: "val2"},
+					{Pos: lexer.Position{Offset: 428, Line: 20, Column: 4}, HasProperties: true, Properties: []*TopicInfoProperty{
+						{Pos: lexer.Position{Offset: 446, Line: 21, Column: 4}, Key: "prop1", Value: "val1"},
+						{Pos: lexer.Position{Offset: 467, Line: 22, Column: 4}, Key: "prop2", Value: "val2"},
 					}},
 				},
 			},
 		}}, ""},
 		{
 			"DropSource", "DROP SOURCE test_source_1",
-			&AST{Drop: &Drop{Source: true, Name: "test_source_1"}}, "",
+			&AST{Drop: &Drop{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Source: true, Name: "test_source_1"}}, "",
 		},
 		{
 			"DropMaterializedView", "DROP MATERIALIZED VIEW test_mv_1",
-			&AST{Drop: &Drop{MaterializedView: true, Name: "test_mv_1"}}, "",
+			&AST{Drop: &Drop{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, MaterializedView: true, Name: "test_mv_1"}}, "",
 		},
 		{
 			"Describe", `DESCRIBE foo`,
@@ -107,15 +112,84 @@ func TestParse(t *testing.T) {
 		},
 		{
 			"ShowTables", `SHOW TABLES`,
-			&AST{Show: &Show{Tables: true}}, "",
+			&AST{Show: &Show{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Tables: true}}, "",
 		},
 		{
 			"ShowSchemas", `SHOW SCHEMAS`,
-			&AST{Show: &Show{Schemas: true}}, "",
+			&AST{Show: &Show{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Schemas: true}}, "",
 		},
 		{
 			"ShowIndexes", `SHOW INDEXES on test_mv1`,
-			&AST{Show: &Show{Indexes: true, TableName: "test_mv1"}}, "",
+			&AST{Show: &Show{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Indexes: true, TableName: &Ref{Pos: lexer.Position{Offset: 16, Line: 1, Column: 17}, Parts: []string{"test_mv1"}}}}, "",
+		},
+		{
+			"ShowIndexesFrom", `SHOW INDEXES FROM test_mv1`,
+			&AST{Show: &Show{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Indexes: true, TableName: &Ref{Pos: lexer.Position{Offset: 18, Line: 1, Column: 19}, Parts: []string{"test_mv1"}}}}, "",
+		},
+		{
+			"ShowFullIndexes", `SHOW FULL INDEXES ON s1.test_mv1`,
+			&AST{Show: &Show{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Full: true, Indexes: true, TableName: &Ref{Pos: lexer.Position{Offset: 21, Line: 1, Column: 22}, Parts: []string{"s1", "test_mv1"}}}}, "",
+		},
+		{
+			"CreateIndex", `CREATE INDEX idx ON t1 (col1, col2)`,
+			&AST{Create: &Create{Pos: lexer.Position{Offset: 7, Line: 1, Column: 8}, Index: &CreateIndex{Pos: lexer.Position{Offset: 13, Line: 1, Column: 14}, Name: "idx", TableName: &Ref{Pos: lexer.Position{Offset: 20, Line: 1, Column: 21}, Parts: []string{"t1"}},
+				ColumnNames: []*ColumnName{{Pos: lexer.Position{Offset: 24, Line: 1, Column: 25}, Name: "col1"}, {Pos: lexer.Position{Offset: 30, Line: 1, Column: 31}, Name: "col2"}}}}}, "",
+		},
+		{
+			"CreateIndexQualified", `CREATE INDEX idx ON s1.t1 (col1)`,
+			&AST{Create: &Create{Pos: lexer.Position{Offset: 7, Line: 1, Column: 8}, Index: &CreateIndex{Pos: lexer.Position{Offset: 13, Line: 1, Column: 14}, Name: "idx", TableName: &Ref{Pos: lexer.Position{Offset: 20, Line: 1, Column: 21}, Parts: []string{"s1", "t1"}},
+				ColumnNames: []*ColumnName{{Pos: lexer.Position{Offset: 27, Line: 1, Column: 28}, Name: "col1"}}}}}, "",
+		},
+		{
+			"DropIndex", `DROP INDEX idx ON t1`,
+			&AST{Drop: &Drop{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Index: true, Name: "idx", TableName: &Ref{Pos: lexer.Position{Offset: 18, Line: 1, Column: 19}, Parts: []string{"t1"}}}}, "",
+		},
+		{
+			"DropIndexQualified", `DROP INDEX idx ON s1.t1`,
+			&AST{Drop: &Drop{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Index: true, Name: "idx", TableName: &Ref{Pos: lexer.Position{Offset: 18, Line: 1, Column: 19}, Parts: []string{"s1", "t1"}}}}, "",
+		},
+		{
+			"ShowStats", `SHOW STATS`,
+			&AST{Show: &Show{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Stats: true}}, "",
+		},
+		{
+			"ShowStatsTerminated", `SHOW STATS;`,
+			&AST{Show: &Show{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Stats: true}}, "",
+		},
+		{
+			"ShowEngineStatus", `SHOW ENGINE STATUS`,
+			&AST{Show: &Show{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Stats: true}}, "",
+		},
+		{
+			"ShowIndexesQualified", `SHOW INDEXES ON s1.t1`,
+			&AST{Show: &Show{Pos: lexer.Position{Offset: 5, Line: 1, Column: 6}, Indexes: true, TableName: &Ref{Pos: lexer.Position{Offset: 16, Line: 1, Column: 17}, Parts: []string{"s1", "t1"}}}}, "",
+		},
+		{
+			"RebuildIndex", `REBUILD INDEX idx ON t1`,
+			&AST{RebuildIndex: &RebuildIndex{Pos: lexer.Position{Offset: 14, Line: 1, Column: 15}, Name: "idx", TableName: &Ref{Pos: lexer.Position{Offset: 21, Line: 1, Column: 22}, Parts: []string{"t1"}}}}, "",
+		},
+		{
+			"RebuildIndexQualified", `REBUILD INDEX idx ON s1.t1;`,
+			&AST{RebuildIndex: &RebuildIndex{Pos: lexer.Position{Offset: 14, Line: 1, Column: 15}, Name: "idx", TableName: &Ref{Pos: lexer.Position{Offset: 21, Line: 1, Column: 22}, Parts: []string{"s1", "t1"}}}}, "",
+		},
+		{
+			"ResetDdl", `RESET DDL test`,
+			&AST{ResetDdl: &ResetDdl{Pos: lexer.Position{Offset: 10, Line: 1, Column: 11}, Schema: "test"}}, "",
+		},
+		{
+			"ResetDdlIfExists", `RESET DDL IF EXISTS test;`,
+			&AST{ResetDdl: &ResetDdl{Pos: lexer.Position{Offset: 10, Line: 1, Column: 11}, IfExists: true, Schema: "test"}}, "",
+		},
+		{
+			"CreatePartialIndex", `CREATE INDEX idx ON t1 (col1) WHERE col2 > 10`,
+			&AST{Create: &Create{Pos: lexer.Position{Offset: 7, Line: 1, Column: 8}, Index: &CreateIndex{Pos: lexer.Position{Offset: 13, Line: 1, Column: 14}, Name: "idx", TableName: &Ref{Pos: lexer.Position{Offset: 20, Line: 1, Column: 21}, Parts: []string{"t1"}},
+				ColumnNames: []*ColumnName{{Pos: lexer.Position{Offset: 24, Line: 1, Column: 25}, Name: "col1"}},
+				Filter: &Expression{Or: []*AndExpression{{And: []*Condition{{Comparison: &Comparison{
+					LHS: &Sum{LHS: &Product{LHS: &Term{Pos: lexer.Position{Offset: 36, Line: 1, Column: 37}, Column: stringRef("col2")}}},
+					Op:  ">",
+					RHS: &Sum{LHS: &Product{LHS: &Term{Pos: lexer.Position{Offset: 43, Line: 1, Column: 44}, Number: floatRef(10)}}},
+				}}}}}},
+			}}}, "",
 		},
 	}
 	for _, test := range tests {
@@ -141,3 +215,455 @@ func intRef(v int) *int {
 func stringRef(v string) *string {
 	return &v
 }
+
+func floatRef(v float64) *float64 {
+	return &v
+}
+
+func TestRawQueryWithSemicolonInString(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{`CREATE MATERIALIZED VIEW mv AS SELECT * FROM t WHERE name = 'a;b'`, ` SELECT * FROM t WHERE name = "a;b"`},
+		{`CREATE MATERIALIZED VIEW mv AS SELECT * FROM t WHERE name = ';'`, ` SELECT * FROM t WHERE name = ";"`},
+		{`CREATE MATERIALIZED VIEW mv AS SELECT * FROM t WHERE name = ';' AND x = 1;`, ` SELECT * FROM t WHERE name = ";" AND x = 1`},
+	}
+	for _, test := range tests {
+		t.Run(test.sql, func(t *testing.T) {
+			ast, err := Parse(test.sql)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, ast.Create.MaterializedView.Query.String())
+		})
+	}
+}
+
+func TestCreateSourceMultipleTopics(t *testing.T) {
+	ast, err := Parse(`CREATE SOURCE s (c bigint, primary key (c)) WITH (
+		BrokerName = "b",
+		TopicName = ("t1", "t2", "t3"),
+		KeyEncoding = "json",
+		ValueEncoding = "json"
+	)`)
+	require.NoError(t, err)
+	opts := ast.Create.Source.OriginInformation
+	require.Equal(t, 4, len(opts))
+	require.Equal(t, "b", opts[0].BrokerName)
+	require.Equal(t, []string{"t1", "t2", "t3"}, opts[1].TopicName)
+	require.Equal(t, "json", opts[2].KeyEncoding)
+	require.Equal(t, "json", opts[3].ValueEncoding)
+}
+
+func TestCreateIndexMalformedFilter(t *testing.T) {
+	_, err := Parse(`CREATE INDEX idx ON t1 (col1) WHERE col2 > > 10`)
+	require.Error(t, err)
+	require.Regexp(t, `^1:\d+: unexpected token`, err.Error())
+}
+
+func TestCreateIndexFilterWithoutSpaces(t *testing.T) {
+	ast, err := Parse(`CREATE INDEX idx ON t1 (col1) WHERE col1+1 > qty-1 AND price*2-1 >= -3 AND -price<0`)
+	require.NoError(t, err)
+	conds := ast.Create.Index.Filter.Or[0].And
+	require.Equal(t, 3, len(conds))
+
+	first := conds[0].Comparison
+	require.Equal(t, "+", first.LHS.Ops[0].Op)
+	require.Equal(t, 1.0, *first.LHS.Ops[0].RHS.LHS.Number)
+	require.Equal(t, "qty", *first.RHS.LHS.LHS.Column)
+	require.Equal(t, "-", first.RHS.Ops[0].Op)
+	require.Equal(t, 1.0, *first.RHS.Ops[0].RHS.LHS.Number)
+	require.Equal(t, lexer.Position{Offset: 49, Line: 1, Column: 50}, first.RHS.Ops[0].RHS.LHS.Pos)
+
+	second := conds[1].Comparison
+	require.Equal(t, "*", second.LHS.LHS.Ops[0].Op)
+	require.Equal(t, "-", second.LHS.Ops[0].Op)
+	require.Equal(t, -3.0, *second.RHS.LHS.LHS.Number)
+
+	third := conds[2].Comparison
+	require.Equal(t, "price", *third.LHS.LHS.LHS.Negative.Column)
+	require.Equal(t, "<", third.Op)
+
+	var columns []string
+	for _, term := range ast.Create.Index.Filter.Columns() {
+		columns = append(columns, *term.Column)
+	}
+	require.Equal(t, []string{"col1", "qty", "price", "price"}, columns)
+}
+
+func TestRef(t *testing.T) {
+	unqualified := &Ref{Parts: []string{"t1"}}
+	require.Equal(t, "t1", unqualified.Name())
+	require.Equal(t, "default", unqualified.Schema("default"))
+
+	qualified := &Ref{Parts: []string{"s1", "t1"}}
+	require.Equal(t, "t1", qualified.Name())
+	require.Equal(t, "s1", qualified.Schema("default"))
+
+	var missing *Ref
+	require.Equal(t, "", missing.Name())
+	require.Equal(t, "default", missing.Schema("default"))
+}
+
+func TestColumnTypeAliases(t *testing.T) {
+	tests := []struct {
+		alias     string
+		canonical string
+	}{
+		{"TEXT", "VARCHAR"},
+		{"STRING", "VARCHAR"},
+		{"FLOAT", "DOUBLE"},
+		{"INTEGER", "INT"},
+		{"text", "varchar"},
+	}
+	for _, test := range tests {
+		t.Run(test.alias, func(t *testing.T) {
+			expected, err := parseColumnDef(t, test.canonical).ToColumnType()
+			require.NoError(t, err)
+			actual, err := parseColumnDef(t, test.alias).ToColumnType()
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestTemporalColumnTypes(t *testing.T) {
+	tests := []struct {
+		colType  string
+		expected common.ColumnType
+		err      string
+	}{
+		{colType: "DATE", expected: common.DateColumnType},
+		{colType: "DATE(3)", err: "1:18: date does not take parameters"},
+		{colType: "TIME", expected: common.NewTimeColumnType(0)},
+		{colType: "TIME(3)", expected: common.NewTimeColumnType(3)},
+		{colType: "TIME(7)", err: "1:18: Time fsp must be >= 0 and <= 6"},
+		{colType: "TIME(300)", err: "1:18: Time fsp must be >= 0 and <= 6"},
+		{colType: "TIMESTAMP(262)", err: "1:18: Timestamp fsp must be >= 0 and <= 6"},
+		{colType: "TIME(3, 2)", err: "1:18: Expected TIME or TIME(fsp)"},
+		{colType: "TIMESTAMP(3, 2)", err: "1:18: Expected TIMESTAMP or TIMESTAMP(fsp)"},
+		{colType: "TIMESTAMP WITH TIME ZONE", expected: common.NewTimestampTZColumnType(0)},
+		{colType: "TIMESTAMP(6) WITH TIME ZONE", expected: common.NewTimestampTZColumnType(6)},
+		{colType: "TIMESTAMP(7) WITH TIME ZONE", err: "1:18: Timestamp fsp must be >= 0 and <= 6"},
+		{colType: "TIME WITH TIME ZONE", err: "1:18: WITH TIME ZONE is only valid for TIMESTAMP"},
+	}
+	for _, test := range tests {
+		t.Run(test.colType, func(t *testing.T) {
+			actual, err := parseColumnDef(t, test.colType).ToColumnType()
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.expected, actual)
+			}
+		})
+	}
+}
+
+// parseColumnDef parses a CREATE SOURCE with a single column of the given type and returns its definition.
+func parseColumnDef(t *testing.T, colType string) *ColumnDef {
+	t.Helper()
+	ast, err := Parse(`CREATE SOURCE s (c ` + colType + `) WITH (BrokerName = "b")`)
+	require.NoError(t, err)
+	return ast.Create.Source.Options[0].Column
+}
+
+func TestSourceSetMaxRate(t *testing.T) {
+	ast, err := Parse("SOURCE SET MAX RATE sensor_readings 1000")
+	require.NoError(t, err)
+	require.Equal(t, "sensor_readings", ast.SourceSetMaxRate.SourceName)
+	require.Equal(t, int64(1000), ast.SourceSetMaxRate.Rate)
+	require.Equal(t, int64(1000), *ast.SourceSetMaxRate.Burst)
+
+	ast, err = Parse("SOURCE SET MAX RATE sensor_readings 1000 BURST 5000")
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), ast.SourceSetMaxRate.Rate)
+	require.Equal(t, int64(5000), *ast.SourceSetMaxRate.Burst)
+
+	_, err = Parse("SOURCE SET MAX RATE sensor_readings 1000 BURST 500")
+	require.EqualError(t, err, "1:21: BURST 500 must not be less than the rate 1000")
+}
+
+func TestEmptyStatement(t *testing.T) {
+	for _, sql := range []string{"", "  \n", ";", " ; ", "-- a comment", "/* a comment */", "-- a comment\n;", "/* a\nmulti-line comment */ ;"} {
+		t.Run(sql, func(t *testing.T) {
+			ast, err := Parse(sql)
+			require.NoError(t, err)
+			require.Equal(t, &AST{Empty: true}, ast)
+		})
+	}
+}
+
+func TestComments(t *testing.T) {
+	ast, err := Parse("-- switch schema\nUSE /* the test schema */ test; -- done")
+	require.NoError(t, err)
+	require.False(t, ast.Empty)
+	require.Equal(t, "test", ast.Use)
+
+	_, err = Parse(";;")
+	require.Error(t, err)
+
+	ast, err = Parse("USE test --")
+	require.NoError(t, err)
+	require.Equal(t, "test", ast.Use)
+
+	// "--" is only a comment when followed by whitespace, so these are subtractions of a negative number.
+	for _, sql := range []string{"CREATE INDEX idx ON t (a) WHERE a--1 > 0", "CREATE INDEX idx ON t (a) WHERE a --1 > 0"} {
+		ast, err = Parse(sql)
+		require.NoError(t, err)
+		sum := ast.Create.Index.Filter.Or[0].And[0].Comparison.LHS
+		require.Equal(t, "a", *sum.LHS.LHS.Column)
+		require.Equal(t, "-", sum.Ops[0].Op)
+		require.Equal(t, -1.0, *sum.Ops[0].RHS.LHS.Number)
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	sql := "CREATE INDEX idx\n  ON s1.t1 (col1)"
+	ast, err := Parse(sql)
+	require.NoError(t, err)
+	require.Equal(t, lexer.Position{Offset: 7, Line: 1, Column: 8}, ast.Create.Pos)
+	require.Equal(t, lexer.Position{Offset: 13, Line: 1, Column: 14}, ast.Create.Index.Pos)
+	require.Equal(t, lexer.Position{Offset: 22, Line: 2, Column: 6}, ast.Create.Index.TableName.Pos)
+
+	sql = `CREATE SOURCE s (c bigint, primary key (c)) WITH (TopicName = "t")`
+	ast, err = Parse(sql)
+	require.NoError(t, err)
+	require.Equal(t, "s (c bigint", sql[ast.Create.Source.Pos.Offset:][:11])
+	require.Equal(t, "c bigint", sql[ast.Create.Source.Options[0].Column.Pos.Offset:][:8])
+	require.Equal(t, "TopicName", sql[ast.Create.Source.OriginInformation[0].Pos.Offset:][:9])
+
+	sql = "DROP INDEX idx ON t1"
+	ast, err = Parse(sql)
+	require.NoError(t, err)
+	require.Equal(t, "INDEX", sql[ast.Drop.Pos.Offset:][:5])
+	require.Equal(t, "t1", sql[ast.Drop.TableName.Pos.Offset:])
+
+	sql = `CREATE MATERIALIZED VIEW mv WITH (InitialState = "s.t") AS SELECT * FROM t`
+	ast, err = Parse(sql)
+	require.NoError(t, err)
+	require.Equal(t, "InitialState", sql[ast.Create.MaterializedView.OriginInformation[0].Pos.Offset:][:12])
+
+	sql = `CREATE SINK sk WITH (TopicName = "t", Properties = ("k" = "v")) AS SELECT * FROM mv`
+	ast, err = Parse(sql)
+	require.NoError(t, err)
+	require.Equal(t, "TopicName", sql[ast.Create.Sink.TargetInformation[0].Pos.Offset:][:9])
+	require.Equal(t, "Properties", sql[ast.Create.Sink.TargetInformation[1].Pos.Offset:][:10])
+	require.Equal(t, `"k"`, sql[ast.Create.Sink.TargetInformation[1].Properties[0].Pos.Offset:][:3])
+
+	sql = "RESET DDL IF EXISTS s1"
+	ast, err = Parse(sql)
+	require.NoError(t, err)
+	require.Equal(t, "IF", sql[ast.ResetDdl.Pos.Offset:][:2])
+}
+
+func TestComplexColumnTypes(t *testing.T) {
+	tests := []struct {
+		colType  string
+		expected common.ColumnType
+		err      string
+	}{
+		{colType: "ARRAY<BIGINT>", expected: common.NewArrayColumnType(common.BigIntColumnType)},
+		// VARCHAR has no length parameter anywhere, including as a top level column type, so neither do element types.
+		{colType: "MAP<VARCHAR(10),DOUBLE>", err: "1:24: varchar does not take parameters"},
+		{colType: "MAP<VARCHAR, DOUBLE>", expected: common.NewMapColumnType(common.VarcharColumnType, common.DoubleColumnType)},
+		{colType: "MAP<VARCHAR, DECIMAL(10, 2)>",
+			expected: common.NewMapColumnType(common.VarcharColumnType, common.NewDecimalColumnType(10, 2))},
+		{colType: "ARRAY<ARRAY<INT>>", expected: common.NewArrayColumnType(common.NewArrayColumnType(common.IntColumnType))},
+		{colType: "STRUCT<name: VARCHAR, readings: ARRAY<DOUBLE>>", expected: common.NewStructColumnType([]common.ColumnInfo{
+			{Name: "name", ColumnType: common.VarcharColumnType},
+			{Name: "readings", ColumnType: common.NewArrayColumnType(common.DoubleColumnType)},
+		})},
+		{colType: "ARRAY<INT, INT>", err: "1:18: Expected ARRAY<element type>"},
+		{colType: "ARRAY", err: "1:18: Expected ARRAY<element type>"},
+		{colType: "MAP<INT>", err: "1:18: Expected MAP<key type, value type>"},
+		{colType: "MAP<ARRAY<INT>, INT>", err: "1:24: MAP key type must not be a complex type"},
+		{colType: "STRUCT<INT>", err: "1:27: STRUCT fields must be named, e.g. STRUCT<name: VARCHAR>"},
+		{colType: "STRUCT<a: INT, A: BIGINT>", err: "1:35: Duplicate STRUCT field a"},
+		{colType: "ARRAY<x: INT>", err: "1:26: Only STRUCT fields can be named"},
+		{colType: "INT<INT>", err: "1:18: int does not take element types"},
+	}
+	for _, test := range tests {
+		t.Run(test.colType, func(t *testing.T) {
+			actual, err := parseColumnDef(t, test.colType).ToColumnType()
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMalformedComplexColumnType(t *testing.T) {
+	for _, colType := range []string{"ARRAY<>", "ARRAY< >", "MAP<INT,>", "ARRAY<INT"} {
+		t.Run(colType, func(t *testing.T) {
+			_, err := Parse(`CREATE SOURCE s (c ` + colType + `) WITH (BrokerName = "b")`)
+			require.Error(t, err)
+			require.Regexp(t, `^1:\d+: unexpected token`, err.Error())
+		})
+	}
+}
+
+func TestMaterializedViewWindowAndEmit(t *testing.T) {
+	ast, err := Parse(`CREATE MATERIALIZED VIEW mv WINDOW TUMBLING (SIZE 1 MINUTE) AS SELECT * FROM t`)
+	require.NoError(t, err)
+	mv := ast.Create.MaterializedView
+	require.NoError(t, mv.Validate())
+	require.Equal(t, "TUMBLING", mv.Window.Type)
+	require.Equal(t, time.Minute, mv.Window.Size.Duration())
+	require.Nil(t, mv.Window.Advance)
+	require.Equal(t, time.Duration(0), mv.Window.Grace.Duration())
+	require.Equal(t, " SELECT * FROM t", mv.Query.String())
+
+	ast, err = Parse(`CREATE MATERIALIZED VIEW mv WINDOW HOPPING (SIZE 1 HOUR, ADVANCE BY 10 MINUTES, GRACE PERIOD 30 SECONDS) AS SELECT * FROM t`)
+	require.NoError(t, err)
+	mv = ast.Create.MaterializedView
+	require.NoError(t, mv.Validate())
+	require.Equal(t, "HOPPING", mv.Window.Type)
+	require.Equal(t, time.Hour, mv.Window.Size.Duration())
+	require.Equal(t, 10*time.Minute, mv.Window.Advance.Duration())
+	require.Equal(t, 30*time.Second, mv.Window.Grace.Duration())
+
+	ast, err = Parse(`CREATE MATERIALIZED VIEW mv EMIT CHANGES AS SELECT * FROM t`)
+	require.NoError(t, err)
+	mv = ast.Create.MaterializedView
+	require.NoError(t, mv.Validate())
+	require.Nil(t, mv.Window)
+	require.Equal(t, "CHANGES", mv.Emit)
+
+	ast, err = Parse(`CREATE MATERIALIZED VIEW mv AS SELECT * FROM t`)
+	require.NoError(t, err)
+	mv = ast.Create.MaterializedView
+	require.NoError(t, mv.Validate())
+	require.Nil(t, mv.Window)
+	require.Equal(t, "", mv.Emit)
+	require.Equal(t, " SELECT * FROM t", mv.Query.String())
+}
+
+func TestMaterializedViewWindowValidation(t *testing.T) {
+	tests := []struct {
+		window string
+		err    string
+	}{
+		{window: "TUMBLING (SIZE 0 SECONDS)", err: "1:36: Window SIZE must be greater than zero"},
+		{window: "TUMBLING (SIZE 1 MINUTE, ADVANCE 10 SECONDS)", err: "1:36: TUMBLING windows do not take an ADVANCE"},
+		{window: "HOPPING (SIZE 1 MINUTE)", err: "1:36: HOPPING windows require an ADVANCE"},
+		{window: "HOPPING (SIZE 1 MINUTE, ADVANCE 2 MINUTES)",
+			err: "1:36: Window ADVANCE must be greater than zero and no larger than SIZE"},
+	}
+	for _, test := range tests {
+		t.Run(test.window, func(t *testing.T) {
+			ast, err := Parse(`CREATE MATERIALIZED VIEW mv WINDOW ` + test.window + ` AS SELECT * FROM t`)
+			require.NoError(t, err)
+			require.EqualError(t, ast.Create.MaterializedView.Validate(), test.err)
+		})
+	}
+}
+
+func TestPositionalParameters(t *testing.T) {
+	sql := "SELECT * FROM t WHERE a = ? AND b = '?' AND c > ?"
+	ast, err := Parse(sql)
+	require.NoError(t, err)
+	require.Equal(t, sql, ast.Select)
+	require.Len(t, ast.Parameters, 2)
+	for _, param := range ast.Parameters {
+		require.True(t, param.Positional)
+		require.Equal(t, "", param.Name)
+	}
+	require.Equal(t, 26, ast.Parameters[0].Pos.Offset)
+	require.Equal(t, 48, ast.Parameters[1].Pos.Offset)
+}
+
+func TestNamedParameters(t *testing.T) {
+	ast, err := Parse("CREATE MATERIALIZED VIEW mv AS SELECT * FROM t WHERE a = :first AND b < :Second AND c = 'x:y'")
+	require.NoError(t, err)
+	require.Len(t, ast.Parameters, 2)
+	require.Equal(t, "first", ast.Parameters[0].Name)
+	require.Equal(t, "Second", ast.Parameters[1].Name)
+	require.False(t, ast.Parameters[0].Positional)
+
+	ast, err = Parse("CREATE INDEX idx ON t (a) WHERE a = :lower OR (b > 1 AND c = ?)")
+	require.NoError(t, err)
+	require.Len(t, ast.Parameters, 2)
+	require.Equal(t, "lower", ast.Parameters[0].Name)
+	require.True(t, ast.Parameters[1].Positional)
+	require.Equal(t, lexer.Position{Offset: 36, Line: 1, Column: 37}, ast.Parameters[0].Pos)
+}
+
+func TestSelectParametersWithUnknownOperators(t *testing.T) {
+	ast, err := Parse("SELECT a | b, c & d, `e` FROM t -- ?\nWHERE f = 'it\\'s ?' AND g ^ ? = :h")
+	require.NoError(t, err)
+	require.Len(t, ast.Parameters, 2)
+	require.Equal(t, &Param{Pos: lexer.Position{Offset: 65, Line: 2, Column: 29}, Positional: true}, ast.Parameters[0])
+	require.Equal(t, &Param{Pos: lexer.Position{Offset: 69, Line: 2, Column: 33}, Name: "h"}, ast.Parameters[1])
+}
+
+func TestGeneratedColumnParameters(t *testing.T) {
+	ast, err := Parse(`CREATE SOURCE s (price double, total double AS (price * ?), primary key (price)) WITH (TopicName = "t")`)
+	require.NoError(t, err)
+	require.Len(t, ast.Parameters, 1)
+	require.Equal(t, lexer.Position{Offset: 56, Line: 1, Column: 57}, ast.Parameters[0].Pos)
+}
+
+func TestGrant(t *testing.T) {
+	ast, err := Parse("GRANT SELECT ON SOURCE s1.sensors TO alice")
+	require.NoError(t, err)
+	grant := ast.Grant
+	require.NotNil(t, grant)
+	require.Len(t, grant.Privileges, 1)
+	require.Equal(t, "SELECT", grant.Privileges[0].Name)
+	require.True(t, grant.Object.Source)
+	require.Equal(t, "s1", grant.Object.Name.Schema("default"))
+	require.Equal(t, "sensors", grant.Object.Name.Name())
+	require.Equal(t, "alice", grant.Principal)
+
+	ast, err = Parse("GRANT CREATE, DROP ON SCHEMA s1 TO 'ops team';")
+	require.NoError(t, err)
+	grant = ast.Grant
+	require.Len(t, grant.Privileges, 2)
+	require.Equal(t, "CREATE", grant.Privileges[0].Name)
+	require.Equal(t, "DROP", grant.Privileges[1].Name)
+	require.True(t, grant.Object.Schema)
+	require.Equal(t, "ops team", grant.Principal)
+
+	_, err = Parse("GRANT SELECT ON SOURCE s1")
+	require.Error(t, err)
+}
+
+func TestRevoke(t *testing.T) {
+	ast, err := Parse("REVOKE ALL PRIVILEGES ON MATERIALIZED VIEW mv FROM bob")
+	require.NoError(t, err)
+	revoke := ast.Revoke
+	require.NotNil(t, revoke)
+	require.Len(t, revoke.Privileges, 1)
+	require.True(t, revoke.Privileges[0].All)
+	require.True(t, revoke.Object.MaterializedView)
+	require.Equal(t, "mv", revoke.Object.Name.Name())
+	require.Equal(t, "bob", revoke.Principal)
+
+	ast, err = Parse("REVOKE SELECT ON SINK out FROM bob")
+	require.NoError(t, err)
+	require.True(t, ast.Revoke.Object.Sink)
+
+	_, err = Parse("REVOKE SELECT ON SINK out TO bob")
+	require.Error(t, err)
+}
+
+func TestExplain(t *testing.T) {
+	ast, err := Parse("EXPLAIN SELECT * FROM t WHERE a = ?")
+	require.NoError(t, err)
+	require.Equal(t, &Explain{Select: "SELECT * FROM t WHERE a = ?"}, ast.Explain)
+	require.Equal(t, "", ast.Select)
+	require.Len(t, ast.Parameters, 1)
+
+	ast, err = Parse("explain  analyze\nselect a, b from t")
+	require.NoError(t, err)
+	require.Equal(t, &Explain{Analyze: true, Select: "select a, b from t"}, ast.Explain)
+
+	_, err = Parse("EXPLAIN DROP SOURCE s")
+	require.EqualError(t, err, "1:1: EXPLAIN only supports SELECT statements")
+
+	_, err = Parse("EXPLAIN ANALYZE")
+	require.Error(t, err)
+}
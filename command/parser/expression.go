@@ -0,0 +1,139 @@
+//nolint:govet
+package parser
+
+import (
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// Expression is a boolean or arithmetic expression, such as the predicate of a WHERE clause.
+//
+// Precedence from loosest to tightest binding is: OR, AND, NOT, comparison, +/-, then */%.
+type Expression struct {
+	Or []*AndExpression `@@ ( "OR" @@ )*`
+}
+
+type AndExpression struct {
+	And []*Condition `@@ ( "AND" @@ )*`
+}
+
+type Condition struct {
+	Not        *Condition  `  "NOT" @@`
+	Comparison *Comparison `| @@`
+}
+
+type Comparison struct {
+	LHS *Sum   `@@`
+	Op  string `( @("<>" | "!=" | "<=" | ">=" | "=" | "<" | ">")`
+	RHS *Sum   `  @@ )?`
+}
+
+type Sum struct {
+	LHS *Product `@@`
+	Ops []*SumOp `@@*`
+}
+
+type SumOp struct {
+	Op  string   `@("+" | "-")`
+	RHS *Product `@@`
+}
+
+type Product struct {
+	LHS *Term        `@@`
+	Ops []*ProductOp `@@*`
+}
+
+type ProductOp struct {
+	Op  string `@("*" | "/" | "%")`
+	RHS *Term  `@@`
+}
+
+type Term struct {
+	Pos lexer.Position
+
+	Number        *float64    `  @Number`
+	String        *string     `| @String`
+	True          bool        `| @"TRUE"`
+	False         bool        `| @"FALSE"`
+	Null          bool        `| @"NULL"`
+	Column        *string     `| @Ident`
+	Param         *Param      `| @@`
+	Negative      *Term       `| "-" @@`
+	SubExpression *Expression `| "(" @@ ")"`
+}
+
+// Param is a bind parameter, either positional, "?", or named, e.g. ":name".
+type Param struct {
+	Pos lexer.Position
+
+	Positional bool   `(  @"?"`
+	Name       string ` | ":" @Ident )`
+}
+
+// Columns returns the terms in the expression that reference a column, in the order they appear.
+func (e *Expression) Columns() []*Term {
+	var columns []*Term
+	e.walk(func(t *Term) {
+		if t.Column != nil {
+			columns = append(columns, t)
+		}
+	})
+	return columns
+}
+
+// Params returns the bind parameters in the expression, in the order they appear.
+func (e *Expression) Params() []*Param {
+	var params []*Param
+	e.walk(func(t *Term) {
+		if t.Param != nil {
+			params = append(params, t.Param)
+		}
+	})
+	return params
+}
+
+// walk calls fn for each term in the expression, in the order they appear. Sub-expressions and negated terms are walked
+// in place of the term that contains them.
+func (e *Expression) walk(fn func(*Term)) {
+	for _, and := range e.Or {
+		for _, cond := range and.And {
+			cond.walk(fn)
+		}
+	}
+}
+
+func (c *Condition) walk(fn func(*Term)) {
+	if c.Not != nil {
+		c.Not.walk(fn)
+		return
+	}
+	c.Comparison.LHS.walk(fn)
+	if c.Comparison.RHS != nil {
+		c.Comparison.RHS.walk(fn)
+	}
+}
+
+func (s *Sum) walk(fn func(*Term)) {
+	s.LHS.walk(fn)
+	for _, op := range s.Ops {
+		op.RHS.walk(fn)
+	}
+}
+
+func (p *Product) walk(fn func(*Term)) {
+	p.LHS.walk(fn)
+	for _, op := range p.Ops {
+		op.RHS.walk(fn)
+	}
+}
+
+func (t *Term) walk(fn func(*Term)) {
+	if t.Negative != nil {
+		t.Negative.walk(fn)
+		return
+	}
+	if t.SubExpression != nil {
+		t.SubExpression.walk(fn)
+		return
+	}
+	fn(t)
+}
@@ -0,0 +1,325 @@
+package parser
+
+import (
+	"math"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/squareup/pranadb/errors"
+)
+
+// Predicate evaluates a compiled filter against a row, given as a map of lower case column name to value.
+type Predicate func(row map[string]interface{}) (bool, error)
+
+// CompileIngestFilter parses an ingest filter, e.g. "sensor_id = 1 AND location <> 'uk'", into a Predicate that can be
+// evaluated repeatedly without re-parsing it.
+//
+// A row matches only if the filter evaluates to true. As in SQL, any comparison involving NULL is unknown, so a row
+// with a NULL in a filtered column does not match.
+func CompileIngestFilter(filter string) (Predicate, error) {
+	expr := &Expression{}
+	if err := expressionParser.ParseString("", filter, expr); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if params := expr.Params(); len(params) > 0 {
+		return nil, participle.Errorf(params[0].Pos, "ingest filter cannot contain bind parameters")
+	}
+	eval := compileExpression(expr)
+	return func(row map[string]interface{}) (bool, error) {
+		v, err := eval(row)
+		if err != nil {
+			return false, err
+		}
+		if v == nil {
+			return false, nil
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return false, errors.Errorf("ingest filter %q does not evaluate to a boolean", filter)
+		}
+		return b, nil
+	}, nil
+}
+
+// evaluator returns the value of an expression for a row. Values are nil (NULL), bool, float64 or string.
+type evaluator func(row map[string]interface{}) (interface{}, error)
+
+func compileExpression(e *Expression) evaluator {
+	terms := make([]evaluator, len(e.Or))
+	for i, and := range e.Or {
+		terms[i] = compileAnd(and)
+	}
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return func(row map[string]interface{}) (interface{}, error) {
+		var result interface{} = false
+		for _, term := range terms {
+			v, err := evalBool(term, row)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				result = nil
+			} else if v.(bool) {
+				return true, nil
+			}
+		}
+		return result, nil
+	}
+}
+
+func compileAnd(a *AndExpression) evaluator {
+	terms := make([]evaluator, len(a.And))
+	for i, cond := range a.And {
+		terms[i] = compileCondition(cond)
+	}
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return func(row map[string]interface{}) (interface{}, error) {
+		var result interface{} = true
+		for _, term := range terms {
+			v, err := evalBool(term, row)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				result = nil
+			} else if !v.(bool) {
+				return false, nil
+			}
+		}
+		return result, nil
+	}
+}
+
+func compileCondition(c *Condition) evaluator {
+	if c.Not != nil {
+		inner := compileCondition(c.Not)
+		return func(row map[string]interface{}) (interface{}, error) {
+			v, err := evalBool(inner, row)
+			if err != nil || v == nil {
+				return nil, err
+			}
+			return !v.(bool), nil
+		}
+	}
+	return compileComparison(c.Comparison)
+}
+
+func compileComparison(c *Comparison) evaluator {
+	lhs := compileSum(c.LHS)
+	if c.Op == "" {
+		return lhs
+	}
+	rhs := compileSum(c.RHS)
+	op := c.Op
+	return func(row map[string]interface{}) (interface{}, error) {
+		l, err := lhs(row)
+		if err != nil {
+			return nil, err
+		}
+		r, err := rhs(row)
+		if err != nil {
+			return nil, err
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		cmp, err := compareValues(l, r, op)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "=":
+			return cmp == 0, nil
+		case "<>", "!=":
+			return cmp != 0, nil
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		case ">=":
+			return cmp >= 0, nil
+		default:
+			panic(op) // The grammar only accepts the operators above.
+		}
+	}
+}
+
+func compileSum(s *Sum) evaluator {
+	result := compileProduct(s.LHS)
+	for _, op := range s.Ops {
+		result = arithmetic(result, compileProduct(op.RHS), op.Op)
+	}
+	return result
+}
+
+func compileProduct(p *Product) evaluator {
+	result := compileTerm(p.LHS)
+	for _, op := range p.Ops {
+		result = arithmetic(result, compileTerm(op.RHS), op.Op)
+	}
+	return result
+}
+
+func compileTerm(t *Term) evaluator {
+	switch {
+	case t.Number != nil:
+		v := *t.Number
+		return constant(v)
+	case t.String != nil:
+		v := *t.String
+		return constant(v)
+	case t.True:
+		return constant(true)
+	case t.False:
+		return constant(false)
+	case t.Null:
+		return constant(nil)
+	case t.Column != nil:
+		name := strings.ToLower(*t.Column)
+		pos := t.Pos
+		return func(row map[string]interface{}) (interface{}, error) {
+			v, ok := row[name]
+			if !ok {
+				return nil, participle.Errorf(pos, "unknown column %s", name)
+			}
+			return normalizeValue(v)
+		}
+	case t.Negative != nil:
+		return negate(compileTerm(t.Negative))
+	case t.SubExpression != nil:
+		return compileExpression(t.SubExpression)
+	default:
+		panic("empty term") // The grammar requires one of the above.
+	}
+}
+
+func constant(v interface{}) evaluator {
+	return func(map[string]interface{}) (interface{}, error) {
+		return v, nil
+	}
+}
+
+func arithmetic(lhs evaluator, rhs evaluator, op string) evaluator {
+	return func(row map[string]interface{}) (interface{}, error) {
+		l, err := lhs(row)
+		if err != nil {
+			return nil, err
+		}
+		r, err := rhs(row)
+		if err != nil {
+			return nil, err
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		lf, lok := l.(float64)
+		rf, rok := r.(float64)
+		if !lok || !rok {
+			return nil, errors.Errorf("operator %s requires numeric operands, got %v and %v", op, l, r)
+		}
+		switch op {
+		case "+":
+			return lf + rf, nil
+		case "-":
+			return lf - rf, nil
+		case "*":
+			return lf * rf, nil
+		case "/":
+			if rf == 0 {
+				return nil, nil
+			}
+			return lf / rf, nil
+		case "%":
+			if rf == 0 {
+				return nil, nil
+			}
+			return math.Mod(lf, rf), nil
+		default:
+			panic(op) // The grammar only accepts the operators above.
+		}
+	}
+}
+
+func negate(e evaluator) evaluator {
+	return func(row map[string]interface{}) (interface{}, error) {
+		v, err := e(row)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, errors.Errorf("operator - requires a numeric operand, got %v", v)
+		}
+		return -f, nil
+	}
+}
+
+// evalBool evaluates e and checks the result is either NULL or a boolean.
+func evalBool(e evaluator, row map[string]interface{}) (interface{}, error) {
+	v, err := e(row)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if _, ok := v.(bool); !ok {
+		return nil, errors.Errorf("expected a boolean but got %v", v)
+	}
+	return v, nil
+}
+
+func compareValues(l interface{}, r interface{}, op string) (int, error) {
+	switch lv := l.(type) {
+	case float64:
+		if rv, ok := r.(float64); ok {
+			switch {
+			case lv < rv:
+				return -1, nil
+			case lv > rv:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	case string:
+		if rv, ok := r.(string); ok {
+			return strings.Compare(lv, rv), nil
+		}
+	case bool:
+		if rv, ok := r.(bool); ok && (op == "=" || op == "<>" || op == "!=") {
+			if lv == rv {
+				return 0, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, errors.Errorf("cannot compare %v %s %v", l, op, r)
+}
+
+// normalizeValue converts a row value to one of the types produced by an evaluator.
+func normalizeValue(v interface{}) (interface{}, error) {
+	switch vv := v.(type) {
+	case nil, bool, float64, string:
+		return vv, nil
+	case int:
+		return float64(vv), nil
+	case int8:
+		return float64(vv), nil
+	case int16:
+		return float64(vv), nil
+	case int32:
+		return float64(vv), nil
+	case int64:
+		return float64(vv), nil
+	case uint64:
+		return float64(vv), nil
+	case float32:
+		return float64(vv), nil
+	default:
+		return nil, errors.Errorf("unsupported value type %T in ingest filter", v)
+	}
+}
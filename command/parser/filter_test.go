@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileIngestFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		row      map[string]interface{}
+		expected bool
+	}{
+		{filter: "sensor_id = 1", row: map[string]interface{}{"sensor_id": int64(1)}, expected: true},
+		{filter: "sensor_id = 1", row: map[string]interface{}{"sensor_id": int64(2)}, expected: false},
+		{filter: "SENSOR_ID > 1 AND location <> 'uk'", row: map[string]interface{}{"sensor_id": int64(2), "location": "us"}, expected: true},
+		{filter: "sensor_id > 1 AND location <> 'uk'", row: map[string]interface{}{"sensor_id": int64(2), "location": "uk"}, expected: false},
+		{filter: "sensor_id = 1 OR location = 'uk'", row: map[string]interface{}{"sensor_id": int64(2), "location": "uk"}, expected: true},
+		{filter: "NOT (sensor_id = 1)", row: map[string]interface{}{"sensor_id": int64(2)}, expected: true},
+		{filter: "temperature * 2 + 1 >= 21", row: map[string]interface{}{"temperature": 10.0}, expected: true},
+		{filter: "temperature % 3 = 1", row: map[string]interface{}{"temperature": int32(7)}, expected: true},
+		{filter: "enabled = true", row: map[string]interface{}{"enabled": true}, expected: true},
+		{filter: "location = 'uk'", row: map[string]interface{}{"location": nil}, expected: false},
+		{filter: "NOT (location = 'uk')", row: map[string]interface{}{"location": nil}, expected: false},
+		{filter: "location = 'uk' OR sensor_id = 1", row: map[string]interface{}{"location": nil, "sensor_id": int64(1)}, expected: true},
+		{filter: "temperature / 0 = 1", row: map[string]interface{}{"temperature": 10.0}, expected: false},
+		{filter: "temperature*2+1 >= 21", row: map[string]interface{}{"temperature": 10.0}, expected: true},
+		{filter: "a-b > 0", row: map[string]interface{}{"a": int64(3), "b": int64(2)}, expected: true},
+		{filter: "a-b > 0", row: map[string]interface{}{"a": int64(2), "b": int64(3)}, expected: false},
+		{filter: "sensor_id > -1", row: map[string]interface{}{"sensor_id": int64(0)}, expected: true},
+		{filter: "-temperature < 0", row: map[string]interface{}{"temperature": 10.0}, expected: true},
+		{filter: "temperature - -1 = 11", row: map[string]interface{}{"temperature": 10.0}, expected: true},
+		{filter: "-temperature < 0", row: map[string]interface{}{"temperature": nil}, expected: false},
+		{filter: "temperature--1 = 11", row: map[string]interface{}{"temperature": 10.0}, expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			pred, err := CompileIngestFilter(test.filter)
+			require.NoError(t, err)
+			matched, err := pred(test.row)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, matched)
+		})
+	}
+}
+
+func TestCompiledIngestFilterIsReusable(t *testing.T) {
+	pred, err := CompileIngestFilter("sensor_id < 3")
+	require.NoError(t, err)
+	for i := int64(0); i < 5; i++ {
+		matched, err := pred(map[string]interface{}{"sensor_id": i})
+		require.NoError(t, err)
+		require.Equal(t, i < 3, matched)
+	}
+}
+
+func TestCompileIngestFilterInvalid(t *testing.T) {
+	for _, filter := range []string{"sensor_id =", "sensor_id = 1 AND", "(sensor_id = 1", "sensor_id = = 1", "sensor_id = ?", "sensor_id = :id"} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := CompileIngestFilter(filter)
+			require.Error(t, err)
+			require.Regexp(t, `^1:\d+: `, err.Error())
+		})
+	}
+}
+
+func TestIngestFilterEvaluationErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		row    map[string]interface{}
+		err    string
+	}{
+		{filter: "missing = 1", row: map[string]interface{}{}, err: "1:1: unknown column missing"},
+		{filter: "location = 1", row: map[string]interface{}{"location": "uk"}, err: "cannot compare uk = 1"},
+		{filter: "sensor_id", row: map[string]interface{}{"sensor_id": int64(1)}, err: `ingest filter "sensor_id" does not evaluate to a boolean`},
+		{filter: "enabled < true", row: map[string]interface{}{"enabled": false}, err: "cannot compare false < true"},
+		{filter: "-location < 0", row: map[string]interface{}{"location": "uk"}, err: "operator - requires a numeric operand, got uk"},
+	}
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			pred, err := CompileIngestFilter(test.filter)
+			require.NoError(t, err)
+			_, err = pred(test.row)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package parser
+
+// StatementKind identifies the type of statement an AST represents.
+type StatementKind int
+
+const (
+	StatementUnknown StatementKind = iota
+	StatementEmpty
+	StatementSelect
+	StatementExplain
+	StatementUse
+	StatementCreateMaterializedView
+	StatementCreateSource
+	StatementCreateSink
+	StatementCreateIndex
+	StatementDropMaterializedView
+	StatementDropSource
+	StatementDropSink
+	StatementDropIndex
+	StatementShowTables
+	StatementShowSchemas
+	StatementShowIndexes
+	StatementShowStats
+	StatementDescribe
+	StatementSourceSetMaxRate
+	StatementRebuildIndex
+	StatementResetDdl
+	StatementGrant
+	StatementRevoke
+)
+
+// Kind returns the kind of statement, or StatementUnknown if the AST has not been populated by Parse.
+//nolint:gocyclo
+func (a *AST) Kind() StatementKind {
+	switch {
+	case a.Empty:
+		return StatementEmpty
+	case a.Select != "":
+		return StatementSelect
+	case a.Explain != nil:
+		return StatementExplain
+	case a.Use != "":
+		return StatementUse
+	case a.Create != nil:
+		return a.Create.kind()
+	case a.Drop != nil:
+		return a.Drop.kind()
+	case a.Show != nil:
+		return a.Show.kind()
+	case a.Describe != "":
+		return StatementDescribe
+	case a.SourceSetMaxRate != nil:
+		return StatementSourceSetMaxRate
+	case a.RebuildIndex != nil:
+		return StatementRebuildIndex
+	case a.ResetDdl != nil:
+		return StatementResetDdl
+	case a.Grant != nil:
+		return StatementGrant
+	case a.Revoke != nil:
+		return StatementRevoke
+	}
+	return StatementUnknown
+}
+
+func (c *Create) kind() StatementKind {
+	switch {
+	case c.MaterializedView != nil:
+		return StatementCreateMaterializedView
+	case c.Source != nil:
+		return StatementCreateSource
+	case c.Sink != nil:
+		return StatementCreateSink
+	case c.Index != nil:
+		return StatementCreateIndex
+	}
+	return StatementUnknown
+}
+
+func (d *Drop) kind() StatementKind {
+	switch {
+	case d.MaterializedView:
+		return StatementDropMaterializedView
+	case d.Source:
+		return StatementDropSource
+	case d.Sink:
+		return StatementDropSink
+	case d.Index:
+		return StatementDropIndex
+	}
+	return StatementUnknown
+}
+
+func (s *Show) kind() StatementKind {
+	switch {
+	case s.Tables:
+		return StatementShowTables
+	case s.Schemas:
+		return StatementShowSchemas
+	case s.Indexes:
+		return StatementShowIndexes
+	case s.Stats:
+		return StatementShowStats
+	}
+	return StatementUnknown
+}
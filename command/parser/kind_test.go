@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementKind(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected StatementKind
+	}{
+		{sql: "", expected: StatementEmpty},
+		{sql: "-- nothing to see here;", expected: StatementEmpty},
+		{sql: "SELECT * FROM t", expected: StatementSelect},
+		{sql: "EXPLAIN ANALYZE SELECT * FROM t", expected: StatementExplain},
+		{sql: "USE s1", expected: StatementUse},
+		{sql: "CREATE MATERIALIZED VIEW mv AS SELECT * FROM t", expected: StatementCreateMaterializedView},
+		{sql: `CREATE SOURCE s (c bigint, primary key (c)) WITH (TopicName = "t")`, expected: StatementCreateSource},
+		{sql: "CREATE SINK sk AS SELECT * FROM mv", expected: StatementCreateSink},
+		{sql: "CREATE INDEX idx ON t (c)", expected: StatementCreateIndex},
+		{sql: "DROP MATERIALIZED VIEW mv", expected: StatementDropMaterializedView},
+		{sql: "DROP SOURCE s", expected: StatementDropSource},
+		{sql: "DROP SINK sk", expected: StatementDropSink},
+		{sql: "DROP INDEX idx ON t", expected: StatementDropIndex},
+		{sql: "SHOW TABLES", expected: StatementShowTables},
+		{sql: "SHOW SCHEMAS", expected: StatementShowSchemas},
+		{sql: "SHOW INDEXES ON t", expected: StatementShowIndexes},
+		{sql: "SHOW ENGINE STATUS", expected: StatementShowStats},
+		{sql: "DESCRIBE t", expected: StatementDescribe},
+		{sql: "SOURCE SET MAX RATE s 100", expected: StatementSourceSetMaxRate},
+		{sql: "REBUILD INDEX idx ON t", expected: StatementRebuildIndex},
+		{sql: "RESET DDL s1", expected: StatementResetDdl},
+		{sql: "GRANT SELECT ON SOURCE s TO alice", expected: StatementGrant},
+		{sql: "REVOKE SELECT ON SOURCE s FROM alice", expected: StatementRevoke},
+	}
+	for _, test := range tests {
+		t.Run(test.sql, func(t *testing.T) {
+			ast, err := Parse(test.sql)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, ast.Kind())
+		})
+	}
+	require.Equal(t, StatementUnknown, (&AST{}).Kind())
+}
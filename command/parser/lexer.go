@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"io"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// signSplitting wraps the statement lexer, whose numbers carry an optional sign so that the grammar can capture
+// negative literals such as DECIMAL(-1, 0) and report them as out of range. A sign directly after an operand is a
+// binary operator though, so "qty-1" is lexed as "qty", "-" and "1" rather than "qty" followed by the number "-1".
+type signSplitting struct {
+	lexer.Definition
+}
+
+func (d *signSplitting) Lex(filename string, r io.Reader) (lexer.Lexer, error) {
+	l, err := d.Definition.Lex(filename, r)
+	if err != nil {
+		return nil, err
+	}
+	return &signSplittingLexer{Lexer: l, symbols: d.Symbols()}, nil
+}
+
+func (d *signSplitting) LexString(filename string, input string) (lexer.Lexer, error) {
+	return d.Lex(filename, strings.NewReader(input))
+}
+
+type signSplittingLexer struct {
+	lexer.Lexer
+	symbols map[string]lexer.TokenType
+	// afterOperand is true if the last token other than whitespace or a comment ends an operand, e.g. "qty" or ")".
+	afterOperand bool
+	// number is the unsigned remainder of a signed number that has been split, to be returned after its sign.
+	number *lexer.Token
+}
+
+func (l *signSplittingLexer) Next() (lexer.Token, error) {
+	if l.number != nil {
+		token := *l.number
+		l.number = nil
+		l.afterOperand = true
+		return token, nil
+	}
+	token, err := l.Lexer.Next()
+	if err != nil {
+		return token, err
+	}
+	switch token.Type {
+	case l.symbols["Whitespace"], l.symbols["Comment"]:
+	case l.symbols["Number"]:
+		if l.afterOperand && (token.Value[0] == '-' || token.Value[0] == '+') {
+			number := token
+			number.Value = token.Value[1:]
+			number.Pos.Offset++
+			number.Pos.Column++
+			l.number = &number
+			token.Type = l.symbols["Punct"]
+			token.Value = token.Value[:1]
+			l.afterOperand = false
+			return token, nil
+		}
+		l.afterOperand = true
+	case l.symbols["Ident"], l.symbols["String"]:
+		l.afterOperand = true
+	default:
+		l.afterOperand = token.Value == ")" || token.Value == "]"
+	}
+	return token, nil
+}
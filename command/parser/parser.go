@@ -2,25 +2,38 @@ package parser
 
 import (
 	"regexp"
+	"unicode/utf8"
 
 	"github.com/squareup/pranadb/errors"
 
 	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/alecthomas/participle/v2/lexer/stateful"
 )
 
 var (
-	lex = stateful.MustSimple([]stateful.Rule{
+	// As in MySQL, "--" only starts a comment if followed by whitespace or the end of the line, so "a--1" is a - -1.
+	lex = &signSplitting{stateful.MustSimple([]stateful.Rule{
+		{`Comment`, `--(?:[ \t\r][^\n]*)?(?m:$)|/\*(?s:.*?)\*/`, nil},
 		{`Ident`, "((?i)[a-zA-Z_][a-zA-Z_0-9]*)|`[^`]*`", nil},
 		{`Number`, `[-+]?\d*\.?\d+([eE][-+]?\d+)?`, nil},
 		{`String`, `'[^']*'|"[^"]*"`, nil},
-		{`Punct`, `<>|!=|<=|>=|\]|\[|[-+*/%,.()=<>;]`, nil},
+		{`Punct`, `<>|!=|<=|>=|\]|\[|[-+*/%,.()=<>;:?]`, nil},
+		{`Whitespace`, `\s+`, nil},
+	})}
+	// Expressions are lexed without signed numbers, as a "-" is always an operator, either binary or unary minus.
+	expressionLex = stateful.MustSimple([]stateful.Rule{
+		{`Comment`, `--(?:[ \t\r][^\n]*)?(?m:$)|/\*(?s:.*?)\*/`, nil},
+		{`Ident`, "((?i)[a-zA-Z_][a-zA-Z_0-9]*)|`[^`]*`", nil},
+		{`Number`, `\d*\.?\d+([eE][-+]?\d+)?`, nil},
+		{`String`, `'[^']*'|"[^"]*"`, nil},
+		{`Punct`, `<>|!=|<=|>=|\]|\[|[-+*/%,.()=<>;:?]`, nil},
 		{`Whitespace`, `\s+`, nil},
 	})
 	parser = participle.MustBuild(&AST{},
 		participle.Lexer(lex),
 		participle.CaseInsensitive("Ident"),
-		participle.Elide("Whitespace"),
+		participle.Elide("Whitespace", "Comment"),
 		participle.UseLookahead(2),
 		// TODO(aat): There's a bug in Participle that prevents us from using this yet:
 		//  any mapping function that mutates a token results in the mutated token being
@@ -34,15 +47,159 @@ var (
 		// }, "Ident"),
 		participle.Unquote("String"),
 	)
-	selectPrefix = regexp.MustCompile(`(?i)^select\s+`)
+	expressionParser = participle.MustBuild(&Expression{},
+		participle.Lexer(expressionLex),
+		participle.CaseInsensitive("Ident"),
+		participle.Elide("Whitespace", "Comment"),
+		participle.UseLookahead(2),
+		participle.Unquote("String"),
+	)
+	selectPrefix  = regexp.MustCompile(`(?i)^select\s+`)
+	explainPrefix = regexp.MustCompile(`(?i)^explain\s+(analyze\s+)?`)
+	// selectSkipped matches text in a SELECT statement that cannot contain a bind parameter: quoted strings and
+	// identifiers, comments, and words, so that the ":" of a named parameter is only recognised at the start of one.
+	selectSkipped = regexp.MustCompile(`^(?:'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"|` + "`[^`]*`" + `|--(?:[ \t\r][^\n]*)?(?m:$)|/\*(?s:.*?)\*/|\w+)`)
+	namedParam    = regexp.MustCompile(`^:([a-zA-Z_][a-zA-Z_0-9]*)`)
 )
 
 // Parse an SQL statement.
 func Parse(sql string) (*AST, error) {
 	if selectPrefix.MatchString(sql) {
-		return &AST{Select: sql}, nil
+		// The query planner reports any error in the statement, including one that fails to lex here.
+		return &AST{Select: sql, Parameters: selectParams(sql)}, nil
+	}
+	if loc := explainPrefix.FindStringSubmatchIndex(sql); loc != nil {
+		query := sql[loc[1]:]
+		if !selectPrefix.MatchString(query) {
+			return nil, errors.WithStack(participle.Errorf(lexer.Position{Line: 1, Column: 1}, "EXPLAIN only supports SELECT statements"))
+		}
+		return &AST{Explain: &Explain{Analyze: loc[2] != -1, Select: query}, Parameters: selectParams(query)}, nil
+	}
+	empty, err := isEmptyStatement(sql)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if empty {
+		return &AST{Empty: true}, nil
 	}
 	ast := &AST{}
-	err := parser.ParseString("", sql, ast)
-	return ast, errors.WithStack(err)
+	if err := parser.ParseString("", sql, ast); err != nil {
+		return ast, errors.WithStack(err)
+	}
+	if ast.SourceSetMaxRate != nil {
+		if err := ast.SourceSetMaxRate.resolveBurst(); err != nil {
+			return ast, errors.WithStack(err)
+		}
+	}
+	ast.Parameters = ast.params()
+	return ast, nil
+}
+
+// params returns the bind parameters in the raw queries and expressions of the statement.
+func (a *AST) params() []*Param {
+	if a.Create == nil {
+		return nil
+	}
+	switch {
+	case a.Create.MaterializedView != nil:
+		return a.Create.MaterializedView.Query.Params()
+	case a.Create.Sink != nil:
+		return a.Create.Sink.Query.Params()
+	case a.Create.Source != nil:
+		return a.Create.Source.params()
+	case a.Create.Index != nil && a.Create.Index.Filter != nil:
+		return a.Create.Index.Filter.Params()
+	}
+	return nil
+}
+
+// params returns the bind parameters in the generated columns and query of the source.
+func (c *CreateSource) params() []*Param {
+	var params []*Param
+	for _, opt := range c.Options {
+		if opt.Column != nil && opt.Column.Generated != nil {
+			params = append(params, opt.Column.Generated.Params()...)
+		}
+	}
+	if c.Query != nil {
+		params = append(params, c.Query.Params()...)
+	}
+	return params
+}
+
+// selectParams returns the bind parameters in a SELECT statement, which is otherwise passed through unparsed. The
+// statement is scanned rather than lexed, as it can contain operators the statement lexer doesn't know, such as "|".
+func selectParams(sql string) []*Param {
+	var params []*Param
+	pos := lexer.Position{Line: 1, Column: 1}
+	for pos.Offset < len(sql) {
+		rest := sql[pos.Offset:]
+		var n int
+		if skipped := selectSkipped.FindString(rest); skipped != "" {
+			n = len(skipped)
+		} else if named := namedParam.FindStringSubmatch(rest); named != nil {
+			params = append(params, &Param{Pos: pos, Name: named[1]})
+			n = len(named[0])
+		} else {
+			if rest[0] == '?' {
+				params = append(params, &Param{Pos: pos, Positional: true})
+			}
+			_, n = utf8.DecodeRuneInString(rest)
+		}
+		for _, r := range rest[:n] {
+			if r == '\n' {
+				pos.Line++
+				pos.Column = 1
+			} else {
+				pos.Column++
+			}
+		}
+		pos.Offset += n
+	}
+	return params
+}
+
+// paramsFromTokens returns the bind parameters in tokens. A named parameter is a ":" immediately followed by an
+// identifier.
+func paramsFromTokens(tokens []lexer.Token) []*Param {
+	symbols := lex.Symbols()
+	punct, ident := symbols["Punct"], symbols["Ident"]
+	var params []*Param
+	for i, token := range tokens {
+		if token.Type != punct {
+			continue
+		}
+		switch {
+		case token.Value == "?":
+			params = append(params, &Param{Pos: token.Pos, Positional: true})
+		case token.Value == ":" && i+1 < len(tokens) && tokens[i+1].Type == ident &&
+			tokens[i+1].Pos.Offset == token.Pos.Offset+1:
+			params = append(params, &Param{Pos: token.Pos, Name: tokens[i+1].Value})
+		}
+	}
+	return params
+}
+
+// isEmptyStatement returns true if sql contains nothing but whitespace, comments and at most one ";".
+func isEmptyStatement(sql string) (bool, error) {
+	lexed, err := lex.LexString("", sql)
+	if err != nil {
+		return false, err
+	}
+	tokens, err := lexer.ConsumeAll(lexed)
+	if err != nil {
+		return false, err
+	}
+	symbols := lex.Symbols()
+	terminators := 0
+	for _, token := range tokens {
+		switch {
+		case token.EOF(), token.Type == symbols["Whitespace"], token.Type == symbols["Comment"]:
+		case token.Value == ";":
+			terminators++
+		default:
+			return false, nil
+		}
+	}
+	return terminators <= 1, nil
 }
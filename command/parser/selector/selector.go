@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/alecthomas/participle/v2/lexer/stateful"
 	"github.com/squareup/pranadb/errors"
 
@@ -19,13 +20,83 @@ import (
 )
 
 type ColumnSelectorAST struct {
-	MetaKey *string      `( "meta" "(" @String ")" |`
-	Field   *string      `  @Ident )`
-	Index   []*Index     `( "[" @@ "]" )*`
-	Next    *SelectorAST `("." @@)?`
+	Cast     *CastAST     `( "CAST" "(" @@ ")" |`
+	Position *PositionAST `  @@ |`
+	MetaKey  *string      `  "meta" "(" @String ")" |`
+	Field    *string      `  @Ident )`
+	Index    []*Index     `( "[" @@ "]" )*`
+	Next     *SelectorAST `("." @@)?`
+}
+
+// Validate checks the parts of the selector the grammar cannot, such as that positions are in range.
+func (s *ColumnSelectorAST) Validate() error {
+	for s.Cast != nil {
+		s = s.Cast.Selector
+	}
+	if s.Position != nil {
+		return s.Position.validate()
+	}
+	return nil
+}
+
+// CastAST is a selector whose value is cast to a column type, e.g. CAST(v.age AS INT). The type is captured as written
+// and validated by the parser package, which owns column types.
+type CastAST struct {
+	Pos lexer.Position
+
+	Selector   *ColumnSelectorAST `@@ "AS"`
+	Type       string             `@Ident`
+	Parameters []int              `("(" @Number ("," @Number)* ")")?`
+}
+
+// PositionAST is a selector that addresses a field of the message body by position rather than by name, for formats
+// such as CSV whose fields are unnamed. field(n) is zero based, like an array index, and csv(n) is one based, like a
+// CSV column number.
+type PositionAST struct {
+	Pos lexer.Position
+
+	Field *int `(  "field" "(" @Number ")"`
+	CSV   *int ` | "csv" "(" @Number ")" )`
+}
+
+// Index returns the zero based position of the field.
+func (p *PositionAST) Index() int {
+	if p.CSV != nil {
+		return *p.CSV - 1
+	}
+	return *p.Field
+}
+
+// String returns the selector as written, e.g. "csv(3)".
+func (p *PositionAST) String() string {
+	if p.CSV != nil {
+		return fmt.Sprintf("csv(%d)", *p.CSV)
+	}
+	return fmt.Sprintf("field(%d)", *p.Field)
+}
+
+func (p *PositionAST) validate() error {
+	switch {
+	case p.Field != nil && *p.Field < 0:
+		return participle.Errorf(p.Pos, "Field position %d must not be negative", *p.Field)
+	case p.CSV != nil && *p.CSV < 1:
+		return participle.Errorf(p.Pos, "CSV position %d must be at least 1", *p.CSV)
+	default:
+		return nil
+	}
 }
 
 func (s *ColumnSelectorAST) ToSelector() ColumnSelector {
+	if s.Cast != nil {
+		return s.Cast.Selector.ToSelector()
+	}
+	if s.Position != nil {
+		index := s.Position.Index()
+		return ColumnSelector{
+			Position: s.Position.String(),
+			Selector: SelectorInjector{{NumberIndex: &index}},
+		}
+	}
 	if s.MetaKey != nil {
 		return ColumnSelector{
 			MetaKey:  s.MetaKey,
@@ -48,6 +119,7 @@ func (s *ColumnSelectorAST) ToSelector() ColumnSelector {
 
 type ColumnSelector struct {
 	MetaKey  *string
+	Position string // Positional selector as written, e.g. "csv(3)". Selector is then the zero based index of the field.
 	Selector SelectorInjector
 }
 
@@ -63,6 +135,9 @@ func (s *ColumnSelector) Select(meta map[string]interface{}, body interface{}) (
 }
 
 func (s ColumnSelector) String() string {
+	if s.Position != "" {
+		return s.Position
+	}
 	var v string
 	if s.MetaKey != nil {
 		v += fmt.Sprintf(`meta("%s")`, *s.MetaKey)
@@ -140,6 +215,9 @@ var columnSelectorParser = participle.MustBuild(&ColumnSelectorAST{},
 func ParseColumnSelector(str string) (ColumnSelector, error) {
 	s := &ColumnSelectorAST{}
 	err := columnSelectorParser.ParseString("", str, s)
+	if err == nil {
+		err = s.Validate()
+	}
 	return s.ToSelector(), errors.WithStack(err)
 }
 
@@ -247,6 +325,9 @@ func (s SelectorInjector) SelectProto(msg pref.Message) (interface{}, error) {
 	if len(s) == 0 {
 		return msg, nil
 	}
+	if s[0].Field == nil {
+		return nil, errors.Errorf("cannot select by position from a protobuf message at %q", s[0:1])
+	}
 	v, f, oneOf, ok := getField(msg, *s[0].Field)
 	if !ok {
 		return nil, &ErrNotFound{missingPath: s[0:1], targetPath: s}
@@ -50,6 +50,26 @@ func TestParseColumnSelector(t *testing.T) {
 			selector: `meta("key").hello.world`,
 			want:     ColumnSelector{MetaKey: stringRef("key"), Selector: newSelector("hello", "world")},
 		},
+		{
+			name:     "field position",
+			selector: `field(3)`,
+			want:     ColumnSelector{Position: "field(3)", Selector: newSelector(3)},
+		},
+		{
+			name:     "csv position",
+			selector: `csv(1)`,
+			want:     ColumnSelector{Position: "csv(1)", Selector: newSelector(0)},
+		},
+		{
+			name:     "negative field position",
+			selector: `field(-1)`,
+			wantErr:  true,
+		},
+		{
+			name:     "zero csv position",
+			selector: `csv(0)`,
+			wantErr:  true,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
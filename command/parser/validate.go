@@ -0,0 +1,295 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/squareup/pranadb/command/parser/selector"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+)
+
+// Validate checks the parts of a CREATE SOURCE statement that can be verified without reference to the rest of the
+// schema.
+func (c *CreateSource) Validate() error {
+	switch {
+	case c.Query != nil && len(c.OriginInformation) > 0:
+		return errors.NewPranaErrorf(errors.InvalidStatement, "Source %s cannot have both WITH options and an AS query", c.Name)
+	case c.Query == nil && len(c.OriginInformation) == 0:
+		return errors.NewPranaErrorf(errors.InvalidStatement, "Source %s requires either WITH options or an AS query", c.Name)
+	default:
+	}
+	if err := c.validateGeneratedColumns(); err != nil {
+		return err
+	}
+	if err := c.validateAutoIncrement(); err != nil {
+		return err
+	}
+	var onError, deadLetterTopic string
+	seen := make(map[string]struct{}, len(c.OriginInformation))
+	for _, opt := range c.OriginInformation {
+		if name := opt.optionName(); name != "" {
+			if _, ok := seen[name]; ok {
+				return participle.Errorf(opt.Pos, "%s specified more than once", name)
+			}
+			seen[name] = struct{}{}
+		}
+		for _, enc := range []string{opt.HeaderEncoding, opt.KeyEncoding, opt.ValueEncoding} {
+			if enc == "" {
+				continue
+			}
+			if err := validateEncoding(enc); err != nil {
+				return err
+			}
+		}
+		for _, sel := range opt.ColSelectors {
+			if err := sel.Validate(); err != nil {
+				return err
+			}
+			for cast := sel.Cast; cast != nil; cast = cast.Selector.Cast {
+				if _, err := CastColumnType(cast); err != nil {
+					return err
+				}
+			}
+		}
+		if opt.RetentionTime != "" {
+			if _, err := ParseRetentionTime(opt.RetentionTime); err != nil {
+				return err
+			}
+		}
+		if opt.CompactionPriority != "" {
+			switch strings.ToLower(opt.CompactionPriority) {
+			case CompactionPriorityLow, CompactionPriorityNormal, CompactionPriorityHigh:
+			default:
+				return errors.NewPranaErrorf(errors.InvalidStatement,
+					"Invalid CompactionPriority value %s, must be one of '%s', '%s' or '%s'", opt.CompactionPriority,
+					CompactionPriorityLow, CompactionPriorityNormal, CompactionPriorityHigh)
+			}
+		}
+		if opt.OnError != "" {
+			onError = strings.ToLower(opt.OnError)
+		}
+		if opt.DeadLetterTopic != "" {
+			deadLetterTopic = opt.DeadLetterTopic
+		}
+	}
+	switch onError {
+	case "", OnErrorFail, OnErrorSkip:
+		if deadLetterTopic != "" {
+			return errors.NewPranaErrorf(errors.InvalidStatement, "DeadLetterTopic requires OnError = '%s'", OnErrorDeadLetter)
+		}
+	case OnErrorDeadLetter:
+		if deadLetterTopic == "" {
+			return errors.NewPranaErrorf(errors.InvalidStatement, "OnError = '%s' requires a DeadLetterTopic", OnErrorDeadLetter)
+		}
+	default:
+		return errors.NewPranaErrorf(errors.InvalidStatement, "Invalid OnError value %s, must be one of '%s', '%s' or '%s'",
+			onError, OnErrorFail, OnErrorSkip, OnErrorDeadLetter)
+	}
+	return nil
+}
+
+// Validate checks the WINDOW and EMIT clauses of a CREATE MATERIALIZED VIEW statement.
+func (c *CreateMaterializedView) Validate() error {
+	if c.Window == nil {
+		return nil
+	}
+	w := c.Window
+	size, advance, grace := w.Size.Duration(), w.Advance.Duration(), w.Grace.Duration()
+	if size <= 0 {
+		return participle.Errorf(w.Pos, "Window SIZE must be greater than zero")
+	}
+	if grace < 0 {
+		return participle.Errorf(w.Pos, "Window GRACE must not be negative")
+	}
+	switch strings.ToLower(w.Type) {
+	case WindowTumbling:
+		if w.Advance != nil {
+			return participle.Errorf(w.Pos, "TUMBLING windows do not take an ADVANCE")
+		}
+	case WindowHopping:
+		if w.Advance == nil {
+			return participle.Errorf(w.Pos, "HOPPING windows require an ADVANCE")
+		}
+		if advance <= 0 || advance > size {
+			return participle.Errorf(w.Pos, "Window ADVANCE must be greater than zero and no larger than SIZE")
+		}
+	default:
+		panic(w.Type) // The grammar only accepts the window types above.
+	}
+	return nil
+}
+
+// validateGeneratedColumns checks that generated columns only reference columns declared in the same statement, other
+// than themselves.
+func (c *CreateSource) validateGeneratedColumns() error {
+	declared := make(map[string]struct{}, len(c.Options))
+	for _, opt := range c.Options {
+		if opt.Column != nil {
+			declared[strings.ToLower(opt.Column.Name)] = struct{}{}
+		}
+	}
+	for _, opt := range c.Options {
+		if opt.Column == nil || opt.Column.Generated == nil {
+			continue
+		}
+		name := strings.ToLower(opt.Column.Name)
+		for _, term := range opt.Column.Generated.Columns() {
+			ref := strings.ToLower(*term.Column)
+			if ref == name {
+				return participle.Errorf(term.Pos, "Generated column %s cannot reference itself", name)
+			}
+			if _, ok := declared[ref]; !ok {
+				return participle.Errorf(term.Pos, "Unknown column %s in generated column %s", ref, name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateAutoIncrement checks there is at most one AUTO_INCREMENT column and that it has an integer type.
+func (c *CreateSource) validateAutoIncrement() error {
+	found := false
+	for _, opt := range c.Options {
+		col := opt.Column
+		if col == nil || !col.AutoIncrement {
+			continue
+		}
+		if found {
+			return participle.Errorf(col.Pos, "Only one AUTO_INCREMENT column is allowed")
+		}
+		found = true
+		switch col.Type {
+		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+		default:
+			return participle.Errorf(col.Pos, "AUTO_INCREMENT column %s must have an integer type", col.Name)
+		}
+		if col.Generated != nil {
+			return participle.Errorf(col.Pos, "Column %s cannot be both AUTO_INCREMENT and generated", col.Name)
+		}
+	}
+	return nil
+}
+
+// CastColumnType returns the column type a column selector is cast to.
+func CastColumnType(cast *selector.CastAST) (common.ColumnType, error) {
+	var typ common.Type
+	if err := typ.Capture([]string{cast.Type}); err != nil {
+		return common.ColumnType{}, participle.Errorf(cast.Pos, "Unknown CAST type %s", cast.Type)
+	}
+	return toColumnType(cast.Pos, typ, nil, cast.Parameters, false)
+}
+
+// resolveBurst defaults Burst to Rate when it's omitted, and rejects a burst that is smaller than the rate.
+func (s *SourceSetMaxRate) resolveBurst() error {
+	if s.Burst == nil {
+		burst := s.Rate
+		s.Burst = &burst
+		return nil
+	}
+	if *s.Burst < s.Rate {
+		return participle.Errorf(s.Pos, "BURST %d must not be less than the rate %d", *s.Burst, s.Rate)
+	}
+	return nil
+}
+
+// optionName returns the name of the WITH option set in o, or "" if it is empty.
+func (o *SourceOriginInformation) optionName() string {
+	switch {
+	case o.BrokerName != "":
+		return "BrokerName"
+	case len(o.TopicName) > 0:
+		return "TopicName"
+	case o.HeaderEncoding != "":
+		return "HeaderEncoding"
+	case o.KeyEncoding != "":
+		return "KeyEncoding"
+	case o.ValueEncoding != "":
+		return "ValueEncoding"
+	case o.IngestFilter != "":
+		return "IngestFilter"
+	case o.InitialState != "":
+		return "InitialState"
+	case o.Transient != nil:
+		return "Transient"
+	case o.StartWithFirstMV != nil:
+		return "StartWithFirstMV"
+	case o.RetentionTime != "":
+		return "RetentionTime"
+	case o.CompactionPriority != "":
+		return "CompactionPriority"
+	case o.DeadLetterTopic != "":
+		return "DeadLetterTopic"
+	case o.OnError != "":
+		return "OnError"
+	case o.HasColSelectors:
+		return "ColumnSelectors"
+	case o.HasProperties:
+		return "Properties"
+	default:
+		return ""
+	}
+}
+
+// validateEncoding checks that a topic encoding is one we know, and that encodings which need a schema to decode
+// against, such as protobuf, name one, e.g. "protobuf:foo.bar.MyMessage".
+func validateEncoding(enc string) error {
+	name, schema := splitEncoding(enc)
+	switch name {
+	case EncodingProtobuf:
+		if schema == "" {
+			return errors.NewPranaErrorf(errors.InvalidStatement,
+				"Topic encoding %s requires a message type, e.g. protobuf:<message name>", enc)
+		}
+	case EncodingAvro:
+		if schema == "" {
+			return errors.NewPranaErrorf(errors.InvalidStatement,
+				"Topic encoding %s requires a schema name, e.g. avro:<schema name>", enc)
+		}
+	case EncodingJSON, EncodingRaw, EncodingCSV, EncodingStringBytes, EncodingFloat32BE, EncodingFloat64BE,
+		EncodingInt16BE, EncodingInt32BE, EncodingInt64BE:
+	default:
+		return errors.NewPranaErrorf(errors.InvalidStatement, "Unknown topic encoding %s", enc)
+	}
+	return nil
+}
+
+// EncodingName returns the encoding of a topic encoding option in lower case, without any schema name, e.g. "protobuf"
+// for "Protobuf:foo.bar.MyMessage".
+func EncodingName(enc string) string {
+	name, _ := splitEncoding(enc)
+	return name
+}
+
+func splitEncoding(enc string) (string, string) {
+	parts := strings.SplitN(enc, ":", 2)
+	if len(parts) == 1 {
+		return strings.ToLower(parts[0]), ""
+	}
+	return strings.ToLower(parts[0]), parts[1]
+}
+
+// ParseRetentionTime parses a RetentionTime option, an integer greater than zero followed by a unit, e.g. "7d" or "12h".
+func ParseRetentionTime(retentionTime string) (time.Duration, error) {
+	sr := strings.Trim(retentionTime, " \t")
+	var dur time.Duration
+	l := len(sr)
+	if l > 1 {
+		if sr[l-1] == 'd' {
+			numDays, err := strconv.Atoi(sr[:l-1])
+			if err == nil {
+				dur = time.Duration(numDays) * time.Hour * 24
+			}
+		} else if !strings.HasSuffix(sr, "ms") && !strings.HasSuffix(sr, "us") && !strings.HasSuffix(sr, "µs") &&
+			!strings.HasSuffix(sr, "ns") {
+			dur, _ = time.ParseDuration(sr)
+		}
+	}
+	if dur <= 0 {
+		return 0, errors.NewPranaErrorf(errors.InvalidStatement, "Invalid RetentionTime %s. Must be an integer > 0 "+
+			"followed by a unit. Valid units are \"d\", \"s\", \"m\", \"h\"", retentionTime)
+	}
+	return dur, nil
+}
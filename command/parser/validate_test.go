@@ -0,0 +1,331 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/squareup/pranadb/command/parser/selector"
+	"github.com/squareup/pranadb/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateEncodings(t *testing.T) {
+	tests := []struct {
+		encoding string
+		err      string
+	}{
+		{encoding: "json"},
+		{encoding: "protobuf:foo.bar.MyMessage"},
+		{encoding: "raw"},
+		{encoding: "csv"},
+		{encoding: "stringbytes"},
+		{encoding: "float32be"},
+		{encoding: "float64be"},
+		{encoding: "int16be"},
+		{encoding: "int32be"},
+		{encoding: "int64be"},
+		{encoding: "JSON"},
+		{encoding: "avro:my_schema"},
+		{encoding: "Avro:my_schema"},
+		{encoding: "avro", err: "PDB1000 - Topic encoding avro requires a schema name, e.g. avro:<schema name>"},
+		{encoding: "foo", err: "PDB1000 - Unknown topic encoding foo"},
+		{encoding: "foo:bar", err: "PDB1000 - Unknown topic encoding foo:bar"},
+		{encoding: "protobuf", err: "PDB1000 - Topic encoding protobuf requires a message type, e.g. protobuf:<message name>"},
+	}
+	for _, test := range tests {
+		for _, option := range []string{"HeaderEncoding", "KeyEncoding", "ValueEncoding"} {
+			t.Run(fmt.Sprintf("%s-%s", option, test.encoding), func(t *testing.T) {
+				err := parseCreateSource(t, fmt.Sprintf(`%s = "%s"`, option, test.encoding)).Validate()
+				if test.err == "" {
+					require.NoError(t, err)
+				} else {
+					require.EqualError(t, err, test.err)
+				}
+			})
+		}
+	}
+}
+
+func TestEncodingName(t *testing.T) {
+	require.Equal(t, EncodingProtobuf, EncodingName("Protobuf:foo.bar.MyMessage"))
+	require.Equal(t, EncodingAvro, EncodingName("avro:my_schema"))
+	require.Equal(t, EncodingJSON, EncodingName("JSON"))
+	require.Equal(t, "", EncodingName(""))
+}
+
+// parseCreateSource parses a CREATE SOURCE statement with the given WITH clause.
+func parseCreateSource(t *testing.T, with string) *CreateSource {
+	t.Helper()
+	ast, err := Parse(`CREATE SOURCE s (c bigint, primary key (c)) WITH (` + with + `)`)
+	require.NoError(t, err)
+	return ast.Create.Source
+}
+
+func TestValidateOnError(t *testing.T) {
+	tests := []struct {
+		name string
+		with string
+		err  string
+	}{
+		{name: "fail", with: `OnError = "fail"`},
+		{name: "skip", with: `OnError = "skip"`},
+		{name: "deadletter", with: `OnError = "deadletter", DeadLetterTopic = "dlq"`},
+		{name: "case insensitive", with: `OnError = "Skip"`},
+		{name: "deadletter without topic", with: `OnError = "deadletter"`,
+			err: "PDB1000 - OnError = 'deadletter' requires a DeadLetterTopic"},
+		{name: "topic without deadletter", with: `DeadLetterTopic = "dlq"`,
+			err: "PDB1000 - DeadLetterTopic requires OnError = 'deadletter'"},
+		{name: "invalid", with: `OnError = "retry"`,
+			err: "PDB1000 - Invalid OnError value retry, must be one of 'fail', 'skip' or 'deadletter'"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := parseCreateSource(t, test.with).Validate()
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestParseDeadLetterOptions(t *testing.T) {
+	src := parseCreateSource(t, `TopicName = "t", OnError = "deadletter", DeadLetterTopic = "dlq"`)
+	require.Equal(t, 3, len(src.OriginInformation))
+	require.Equal(t, "deadletter", src.OriginInformation[1].OnError)
+	require.Equal(t, "dlq", src.OriginInformation[2].DeadLetterTopic)
+}
+
+func TestValidateDuplicateOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		with string
+		err  string
+	}{
+		{name: "distinct", with: `BrokerName = "b", TopicName = "t", KeyEncoding = "json", ValueEncoding = "json"`},
+		{name: "topic", with: `TopicName = "a", TopicName = "b"`, err: "1:68: TopicName specified more than once"},
+		{name: "transient", with: `BrokerName = "b", KeyEncoding = "json", Transient = true, Transient = false`,
+			err: "1:109: Transient specified more than once"},
+		{name: "empty column selectors", with: `ColumnSelectors = (), ColumnSelectors = (v0)`,
+			err: "1:73: ColumnSelectors specified more than once"},
+		{name: "empty properties", with: `Properties = (), Properties = ()`,
+			err: "1:68: Properties specified more than once"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := parseCreateSource(t, test.with).Validate()
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestGeneratedColumns(t *testing.T) {
+	ast, err := Parse(`CREATE SOURCE s (price double, qty bigint, total double AS (price * qty), primary key (qty)) WITH (TopicName = "t")`)
+	require.NoError(t, err)
+	src := ast.Create.Source
+	require.NoError(t, src.Validate())
+	total := src.Options[2].Column
+	require.Equal(t, "total", total.Name)
+	require.NotNil(t, total.Generated)
+	var columns []string
+	for _, term := range total.Generated.Columns() {
+		columns = append(columns, *term.Column)
+	}
+	require.Equal(t, []string{"price", "qty"}, columns)
+	require.Nil(t, src.Options[0].Column.Generated)
+
+	ast, err = Parse(`CREATE SOURCE s (price double, qty bigint, total double AS (price*2+1), prior bigint AS (qty-1), primary key (qty)) WITH (TopicName = "t")`)
+	require.NoError(t, err)
+	require.NoError(t, ast.Create.Source.Validate())
+	prior := ast.Create.Source.Options[3].Column.Generated.Or[0].And[0].Comparison.LHS
+	require.Equal(t, "qty", *prior.LHS.LHS.Column)
+	require.Equal(t, "-", prior.Ops[0].Op)
+	require.Equal(t, 1.0, *prior.Ops[0].RHS.LHS.Number)
+}
+
+func TestGeneratedColumnValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		err  string
+	}{
+		{name: "unknown column",
+			sql: `CREATE SOURCE s (price double, qty bigint, total double AS (price * count), primary key (qty)) WITH (TopicName = "t")`,
+			err: "1:69: Unknown column count in generated column total"},
+		{name: "self reference",
+			sql: `CREATE SOURCE s (price double, total double AS (total + 1), primary key (price)) WITH (TopicName = "t")`,
+			err: "1:49: Generated column total cannot reference itself"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ast, err := Parse(test.sql)
+			require.NoError(t, err)
+			require.EqualError(t, ast.Create.Source.Validate(), test.err)
+		})
+	}
+}
+
+func TestDerivedSource(t *testing.T) {
+	ast, err := Parse(`CREATE SOURCE s (c bigint, primary key (c)) WITH (TopicName = "t")`)
+	require.NoError(t, err)
+	require.Nil(t, ast.Create.Source.Query)
+	require.NoError(t, ast.Create.Source.Validate())
+
+	ast, err = Parse(`CREATE SOURCE s (c bigint, primary key (c)) AS SELECT c FROM other WHERE c > 10`)
+	require.NoError(t, err)
+	require.Nil(t, ast.Create.Source.OriginInformation)
+	require.Equal(t, " SELECT c FROM other WHERE c > 10", ast.Create.Source.Query.String())
+	require.NoError(t, ast.Create.Source.Validate())
+
+	ast, err = Parse(`CREATE SOURCE s (c bigint, primary key (c)) WITH (TopicName = "t") AS SELECT c FROM other`)
+	require.NoError(t, err)
+	require.EqualError(t, ast.Create.Source.Validate(), "PDB1000 - Source s cannot have both WITH options and an AS query")
+
+	ast, err = Parse(`CREATE SOURCE s (c bigint, primary key (c))`)
+	require.NoError(t, err)
+	require.EqualError(t, ast.Create.Source.Validate(), "PDB1000 - Source s requires either WITH options or an AS query")
+}
+
+func TestAutoIncrementColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		err  string
+	}{
+		{name: "auto_increment",
+			sql: `CREATE SOURCE s (a bigint AUTO_INCREMENT, b varchar, primary key (a)) WITH (TopicName = "t")`},
+		{name: "identity",
+			sql: `CREATE SOURCE s (a int GENERATED ALWAYS AS IDENTITY, b varchar, primary key (a)) WITH (TopicName = "t")`},
+		{name: "more than one",
+			sql: `CREATE SOURCE s (a bigint AUTO_INCREMENT, b int AUTO_INCREMENT, primary key (a)) WITH (TopicName = "t")`,
+			err: "1:43: Only one AUTO_INCREMENT column is allowed"},
+		{name: "not an integer",
+			sql: `CREATE SOURCE s (a varchar AUTO_INCREMENT, primary key (a)) WITH (TopicName = "t")`,
+			err: "1:18: AUTO_INCREMENT column a must have an integer type"},
+		{name: "generated",
+			sql: `CREATE SOURCE s (b bigint, a bigint AUTO_INCREMENT AS (b + 1), primary key (a)) WITH (TopicName = "t")`,
+			err: "1:28: Column a cannot be both AUTO_INCREMENT and generated"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ast, err := Parse(test.sql)
+			require.NoError(t, err)
+			src := ast.Create.Source
+			err = src.Validate()
+			if test.err == "" {
+				require.NoError(t, err)
+				require.True(t, src.Options[0].Column.AutoIncrement)
+				require.False(t, src.Options[1].Column.AutoIncrement)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestColumnSelectorCasts(t *testing.T) {
+	tests := []struct {
+		selector string
+		expected common.ColumnType
+		err      string
+	}{
+		{selector: `CAST(v.person.age AS INT)`, expected: common.IntColumnType},
+		{selector: `CAST(meta("key").k0 AS BIGINT)`, expected: common.BigIntColumnType},
+		{selector: `CAST(v.price AS DECIMAL(10, 2))`, expected: common.NewDecimalColumnType(10, 2)},
+		{selector: `CAST(v.foo AS BLOB)`, err: "1:75: Unknown CAST type BLOB"},
+		{selector: `CAST(v.foo AS DECIMAL)`, err: "1:75: Expected DECIMAL(precision, scale)"},
+	}
+	for _, test := range tests {
+		t.Run(test.selector, func(t *testing.T) {
+			src := parseCreateSource(t, "ColumnSelectors = ("+test.selector+")")
+			err := src.Validate()
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			cast := src.OriginInformation[0].ColSelectors[0].Cast
+			require.NotNil(t, cast)
+			actual, err := CastColumnType(cast)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestNestedColumnSelectorCast(t *testing.T) {
+	src := parseCreateSource(t, `ColumnSelectors = (CAST(v.person["age"] AS INT), v2)`)
+	require.NoError(t, src.Validate())
+	selectors := src.OriginInformation[0].ColSelectors
+	require.Equal(t, 2, len(selectors))
+	cast := selectors[0].Cast
+	require.Equal(t, "INT", cast.Type)
+	require.Equal(t, &selector.ColumnSelectorAST{Field: stringRef("v"), Next: &selector.SelectorAST{
+		Field: "person",
+		Index: []*selector.Index{{String: stringRef("age")}},
+	}}, cast.Selector)
+	require.Nil(t, selectors[1].Cast)
+}
+
+func TestValidateRetentionTime(t *testing.T) {
+	tests := []struct {
+		name string
+		with string
+		err  string
+	}{
+		{name: "days", with: `RetentionTime = "7d"`},
+		{name: "hours", with: `RetentionTime = "12h"`},
+		{name: "no unit", with: `RetentionTime = "1000"`,
+			err: `PDB1000 - Invalid RetentionTime 1000. Must be an integer > 0 followed by a unit. Valid units are "d", "s", "m", "h"`},
+		{name: "negative", with: `RetentionTime = "-1d"`,
+			err: `PDB1000 - Invalid RetentionTime -1d. Must be an integer > 0 followed by a unit. Valid units are "d", "s", "m", "h"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := parseCreateSource(t, test.with).Validate()
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+	retention, err := ParseRetentionTime("7d")
+	require.NoError(t, err)
+	require.Equal(t, 7*24*time.Hour, retention)
+}
+
+func TestValidateCompactionPriority(t *testing.T) {
+	for _, priority := range []string{CompactionPriorityLow, CompactionPriorityNormal, CompactionPriorityHigh, "High"} {
+		t.Run(priority, func(t *testing.T) {
+			src := parseCreateSource(t, `RetentionTime = "1d", CompactionPriority = "`+priority+`"`)
+			require.Equal(t, priority, src.OriginInformation[1].CompactionPriority)
+			require.NoError(t, src.Validate())
+		})
+	}
+	err := parseCreateSource(t, `CompactionPriority = "urgent"`).Validate()
+	require.EqualError(t, err, "PDB1000 - Invalid CompactionPriority value urgent, must be one of 'low', 'normal' or 'high'")
+}
+
+func TestPositionalColumnSelectors(t *testing.T) {
+	src := parseCreateSource(t, `ColumnSelectors = (field(0), v.name, csv(3))`)
+	require.NoError(t, src.Validate())
+	selectors := src.OriginInformation[0].ColSelectors
+	require.Len(t, selectors, 3)
+	first, second, third := selectors[0].ToSelector(), selectors[1].ToSelector(), selectors[2].ToSelector()
+	require.Equal(t, "field(0)", first.String())
+	require.Equal(t, 0, *first.Selector[0].NumberIndex)
+	require.Equal(t, "", second.Position)
+	require.Equal(t, "v.name", second.Selector.String())
+	require.Equal(t, "csv(3)", third.String())
+	require.Equal(t, 2, *third.Selector[0].NumberIndex)
+
+	err := parseCreateSource(t, `ColumnSelectors = (v, field(-2))`).Validate()
+	require.EqualError(t, err, "1:73: Field position -2 must not be negative")
+}
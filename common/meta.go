@@ -23,6 +23,12 @@ const (
 	TypeDecimal
 	TypeVarchar
 	TypeTimestamp
+	TypeDate
+	TypeTime
+	TypeTimestampTZ
+	TypeArray
+	TypeMap
+	TypeStruct
 )
 
 func (t *Type) Capture(tokens []string) error {
@@ -30,18 +36,28 @@ func (t *Type) Capture(tokens []string) error {
 	switch text {
 	case "TINYINT":
 		*t = TypeTinyInt
-	case "INT":
+	case "INT", "INTEGER":
 		*t = TypeInt
 	case "BIGINT":
 		*t = TypeBigInt
-	case "VARCHAR":
+	case "VARCHAR", "TEXT", "STRING":
 		*t = TypeVarchar
 	case "DECIMAL":
 		*t = TypeDecimal
-	case "DOUBLE":
+	case "DOUBLE", "FLOAT":
 		*t = TypeDouble
 	case "TIMESTAMP":
 		*t = TypeTimestamp
+	case "DATE":
+		*t = TypeDate
+	case "TIME":
+		*t = TypeTime
+	case "ARRAY":
+		*t = TypeArray
+	case "MAP":
+		*t = TypeMap
+	case "STRUCT":
+		*t = TypeStruct
 	default:
 		return errors.Errorf("unknown column type %s", text)
 	}
@@ -65,6 +81,18 @@ func (t Type) String() string {
 		return "varchar"
 	case TypeTimestamp:
 		return "timestamp"
+	case TypeDate:
+		return "date"
+	case TypeTime:
+		return "time"
+	case TypeTimestampTZ:
+		return "timestamp with time zone"
+	case TypeArray:
+		return "array"
+	case TypeMap:
+		return "map"
+	case TypeStruct:
+		return "struct"
 	case TypeUnknown:
 	}
 	return "unknown"
@@ -77,6 +105,7 @@ var (
 	DoubleColumnType    = ColumnType{Type: TypeDouble}
 	VarcharColumnType   = ColumnType{Type: TypeVarchar}
 	TimestampColumnType = ColumnType{Type: TypeTimestamp}
+	DateColumnType      = ColumnType{Type: TypeDate}
 	UnknownColumnType   = ColumnType{Type: TypeUnknown}
 
 	// ColumnTypesByType allows lookup of non-parameterised ColumnType by Type.
@@ -86,6 +115,7 @@ var (
 		TypeBigInt:  BigIntColumnType,
 		TypeDouble:  DoubleColumnType,
 		TypeVarchar: VarcharColumnType,
+		TypeDate:    DateColumnType,
 	}
 )
 
@@ -104,6 +134,41 @@ func NewTimestampColumnType(fsp int8) ColumnType {
 	}
 }
 
+func NewTimestampTZColumnType(fsp int8) ColumnType {
+	return ColumnType{
+		Type: TypeTimestampTZ,
+		FSP:  fsp,
+	}
+}
+
+func NewTimeColumnType(fsp int8) ColumnType {
+	return ColumnType{
+		Type: TypeTime,
+		FSP:  fsp,
+	}
+}
+
+func NewArrayColumnType(elementType ColumnType) ColumnType {
+	return ColumnType{
+		Type:    TypeArray,
+		Complex: &ComplexType{Elements: []ColumnInfo{{ColumnType: elementType}}},
+	}
+}
+
+func NewMapColumnType(keyType ColumnType, valueType ColumnType) ColumnType {
+	return ColumnType{
+		Type:    TypeMap,
+		Complex: &ComplexType{Elements: []ColumnInfo{{ColumnType: keyType}, {ColumnType: valueType}}},
+	}
+}
+
+func NewStructColumnType(fields []ColumnInfo) ColumnType {
+	return ColumnType{
+		Type:    TypeStruct,
+		Complex: &ComplexType{Elements: fields},
+	}
+}
+
 type ColumnInfo struct {
 	Name string
 	ColumnType
@@ -113,7 +178,15 @@ type ColumnType struct {
 	Type         Type
 	DecPrecision int
 	DecScale     int
-	FSP          int8 // fractional seconds precision for time types
+	FSP          int8         // fractional seconds precision for time types
+	Complex      *ComplexType // element types for ARRAY, MAP and STRUCT, nil otherwise
+}
+
+// ComplexType holds the element types of an ARRAY, MAP or STRUCT column type. It is referenced by pointer so that
+// ColumnType remains comparable.
+type ComplexType struct {
+	// Elements is the element type of an ARRAY, the key and value types of a MAP, or the named fields of a STRUCT.
+	Elements []ColumnInfo
 }
 
 func (t *ColumnType) String() string {
@@ -121,8 +194,29 @@ func (t *ColumnType) String() string {
 	switch t.Type {
 	case TypeDecimal:
 		return fmt.Sprintf("%s(%d, %d)", typeName, t.DecPrecision, t.DecScale)
-	case TypeTimestamp:
+	case TypeTimestamp, TypeTime:
 		return fmt.Sprintf("%s(%d)", typeName, t.FSP)
+	case TypeTimestampTZ:
+		return fmt.Sprintf("timestamp(%d) with time zone", t.FSP)
+	case TypeArray, TypeMap, TypeStruct:
+		if t.Complex == nil {
+			return typeName
+		}
+		sb := strings.Builder{}
+		sb.WriteString(typeName)
+		sb.WriteString("<")
+		for i, element := range t.Complex.Elements {
+			if i != 0 {
+				sb.WriteString(", ")
+			}
+			if element.Name != "" {
+				sb.WriteString(element.Name)
+				sb.WriteString(": ")
+			}
+			sb.WriteString(element.ColumnType.String())
+		}
+		sb.WriteString(">")
+		return sb.String()
 	default:
 	}
 	return typeName
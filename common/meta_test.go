@@ -8,6 +8,7 @@ func TestColumnType_String(t *testing.T) {
 		DecPrecision int
 		DecScale     int
 		FSP          int8
+		Complex      *ComplexType
 	}
 	tests := []struct {
 		name   string
@@ -49,6 +50,30 @@ func TestColumnType_String(t *testing.T) {
 			fields: fields{Type: TypeTimestamp, FSP: 6},
 			want:   "timestamp(6)",
 		},
+		{
+			name:   "array",
+			fields: fields{Type: TypeArray, Complex: &ComplexType{Elements: []ColumnInfo{{ColumnType: BigIntColumnType}}}},
+			want:   "array<bigint>",
+		},
+		{
+			name: "map",
+			fields: fields{Type: TypeMap, Complex: &ComplexType{Elements: []ColumnInfo{
+				{ColumnType: VarcharColumnType}, {ColumnType: NewArrayColumnType(DoubleColumnType)},
+			}}},
+			want: "map<varchar, array<double>>",
+		},
+		{
+			name: "struct",
+			fields: fields{Type: TypeStruct, Complex: &ComplexType{Elements: []ColumnInfo{
+				{Name: "a", ColumnType: IntColumnType}, {Name: "b", ColumnType: NewDecimalColumnType(10, 2)},
+			}}},
+			want: "struct<a: int, b: decimal(10, 2)>",
+		},
+		{
+			name:   "map without element types",
+			fields: fields{Type: TypeMap},
+			want:   "map",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -57,6 +82,7 @@ func TestColumnType_String(t *testing.T) {
 				DecPrecision: tt.fields.DecPrecision,
 				DecScale:     tt.fields.DecScale,
 				FSP:          tt.fields.FSP,
+				Complex:      tt.fields.Complex,
 			}
 			if got := tr.String(); got != tt.want {
 				t.Errorf("ColumnType.String() = %v, want %v", got, tt.want)